@@ -1,34 +1,47 @@
 package apis
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
+	"strconv"
 
 	"github.com/EmilyShepherd/k8s-client-go/pkg/client"
+	"github.com/EmilyShepherd/k8s-client-go/pkg/codec"
 	"github.com/EmilyShepherd/k8s-client-go/pkg/stream"
 	"github.com/EmilyShepherd/k8s-client-go/types"
 )
 
+// ResponseDecoderFunc creates a ResponseDecoder for a response body
 type ResponseDecoderFunc func(r io.Reader) ResponseDecoder
 
-func NewObjectAPI[T any, PT types.Object[T]](kc *client.Client, gvr types.GroupVersionResource) types.ObjectAPI[T, PT] {
+func NewObjectAPI[T any, PT types.Object[T]](kc *client.Client, gvr types.GroupVersionResource, opts ...Option) types.ObjectAPI[T, PT] {
+	var options apiOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	// The preferred codec is ignored if it can't handle the type at all,
+	// such as protobuf with a CRD type.
+	if options.codec != nil && !codec.Supports(options.codec, new(T)) {
+		options.codec = nil
+	}
+
 	return &objectAPI[T, PT]{
-		kc:  kc,
-		gvr: gvr,
-		responseDecodeFunc: func(r io.Reader) ResponseDecoder {
-			return json.NewDecoder(r)
-		},
+		kc:     kc,
+		gvr:    gvr,
+		codecs: newNegotiator(options.codec, codec.NewJSON(options.json)),
 	}
 }
 
 type objectAPI[T any, PT types.Object[T]] struct {
-	kc                 *client.Client
-	responseDecodeFunc ResponseDecoderFunc
-	gvr                types.GroupVersionResource
-	subresource        string
+	kc          *client.Client
+	codecs      *negotiator
+	gvr         types.GroupVersionResource
+	subresource string
 }
 
 func (o *objectAPI[T, PT]) Subresource(subresource string) types.ObjectAPI[T, PT] {
@@ -42,64 +55,139 @@ func (o *objectAPI[T, PT]) Status() types.ObjectAPI[T, PT] {
 	return o.Subresource("status")
 }
 
-func (o *objectAPI[T, PT]) doAndUnmarshal(item interface{}, req client.ResourceRequest) (*http.Response, error) {
+func (o *objectAPI[T, PT]) resource() string {
+	return o.gvr.Resource
+}
+
+// doAndUnmarshal sends the request, encoding body as its body if it is
+// given, and decodes the response into item. If the apiserver rejects the
+// preferred codec, the request is tried again with JSON.
+func (o *objectAPI[T, PT]) doAndUnmarshal(ctx context.Context, item any, req client.ResourceRequest, body *T) (*http.Response, error) {
 	req.GVR = o.gvr
 	req.Subresource = o.subresource
-	resp, err := o.kc.Do(req)
-	if err != nil {
+
+	for {
+		codecs := o.codecs.Codecs()
+		req.Accept = codec.Accept(false, codecs...)
+		if body != nil {
+			if err := encode(&req, codecs, body); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := o.kc.Do(ctx, req)
+		if err != nil {
+			if o.codecs.fallback(codecs, err) {
+				continue
+			}
+			return resp, err
+		}
+
+		defer resp.Body.Close()
+
+		c := codec.ForContentType(resp.Header.Get("Content-Type"), codecs...)
+		err = c.NewDecoder(resp.Body).Decode(item)
+
 		return resp, err
 	}
+}
+
+// encode sets the request's body to the encoded item. New objects and
+// apply patches are encoded with the preferred codec if it supports them,
+// and JSON, which is always the last codec, otherwise. Merge patches are
+// always JSON.
+func encode(req *client.ResourceRequest, codecs []codec.Codec, item any) error {
+	c, json := codecs[0], codecs[len(codecs)-1]
+
+	ap, canApply := c.(codec.ApplyPatcher)
+	if req.ContentType == client.MergePatchContentType || (req.IsApply() && !canApply) {
+		c = json
+	}
 
-	defer resp.Body.Close()
+	body, err := c.Marshal(item)
+	if errors.Is(err, codec.ErrUnsupported) && c != json {
+		return encode(req, codecs[len(codecs)-1:], item)
+	}
+	if err != nil {
+		return err
+	}
 
-	err = o.responseDecodeFunc(resp.Body).Decode(item)
+	switch {
+	case req.IsApply() && c == json:
+		req.ContentType = client.ApplyPatchContentType
+	case req.IsApply():
+		req.ContentType = client.ContentType(ap.ApplyPatchMediaType())
+	case req.ContentType != client.MergePatchContentType:
+		req.ContentType = client.ContentType(c.MediaType())
+	}
+	req.Body = body
 
-	return resp, err
+	return nil
 }
 
-func (o *objectAPI[T, PT]) doAndUnmarshalItem(req client.ResourceRequest) (T, error) {
+func (o *objectAPI[T, PT]) doAndUnmarshalItem(ctx context.Context, req client.ResourceRequest) (T, error) {
 	var t T
-	_, err := o.doAndUnmarshal(&t, req)
+	_, err := o.doAndUnmarshal(ctx, &t, req, nil)
 	return t, err
 }
 
-func (o *objectAPI[T, PT]) Get(namespace, name string, opts types.GetOptions) (T, error) {
-	return o.doAndUnmarshalItem(client.ResourceRequest{
+func (o *objectAPI[T, PT]) Get(ctx context.Context, namespace, name string, opts types.GetOptions) (T, error) {
+	return o.doAndUnmarshalItem(ctx, client.ResourceRequest{
 		Namespace: namespace,
 		Name:      name,
 	})
 }
 
-func (o *objectAPI[T, PT]) List(namespace string, opts types.ListOptions) (*types.List[T, PT], error) {
-	q := url.Values{}
-	for _, label := range opts.LabelSelector {
-		if label.Operator == types.Exists {
-			q.Add("labelSelector=", label.Label)
-		} else {
-			q.Add("labelSelector=", label.Label+label.Operator+label.Value)
-		}
+func (o *objectAPI[T, PT]) List(ctx context.Context, namespace string, opts types.ListOptions) (*types.List[T, PT], error) {
+	q := selectorValues(opts)
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.FormatInt(opts.Limit, 10))
+	}
+	if opts.Continue != "" {
+		q.Set("continue", opts.Continue)
 	}
 
 	var t types.List[T, PT]
-	_, err := o.doAndUnmarshal(&t, client.ResourceRequest{
+	_, err := o.doAndUnmarshal(ctx, &t, client.ResourceRequest{
 		Namespace: namespace,
 		Values:    q,
-	})
+	}, nil)
 	return &t, err
 }
 
-func (o *objectAPI[T, PT]) Create(namespace string, item T) (T, error) {
-	s, _ := json.Marshal(item)
-	return o.doAndUnmarshalItem(client.ResourceRequest{
+// selectorValues returns the query parameters for the selectors in opts,
+// which are shared by List and Watch.
+func selectorValues(opts types.ListOptions) url.Values {
+	q := url.Values{}
+	if selector := types.EncodeLabelSelector(opts.LabelSelector); selector != "" {
+		q.Set("labelSelector", selector)
+	}
+	if selector := types.EncodeFieldSelector(opts.FieldSelector); selector != "" {
+		q.Set("fieldSelector", selector)
+	}
+
+	return q
+}
+
+// Create POSTs the item to the collection. When used on a subresource,
+// such as serviceaccounts/token or pods/eviction, the item is instead
+// POSTed to that subresource of the object with the item's name.
+func (o *objectAPI[T, PT]) Create(ctx context.Context, namespace string, item T) (T, error) {
+	var name string
+	if o.subresource != "" {
+		name = PT(&item).GetName()
+	}
+
+	var t T
+	_, err := o.doAndUnmarshal(ctx, &t, client.ResourceRequest{
 		Verb:      "POST",
 		Namespace: namespace,
-		Body:      bytes.NewReader(s),
-	})
+		Name:      name,
+	}, &item)
+	return t, err
 }
 
-func (o *objectAPI[T, PT]) patch(namespace, name, fieldManager string, force bool, ct client.ContentType, item T) (T, *http.Response, error) {
-	s, _ := json.Marshal(item)
-
+func (o *objectAPI[T, PT]) patch(ctx context.Context, namespace, name, fieldManager string, force bool, ct client.ContentType, item T) (T, *http.Response, error) {
 	q := url.Values{}
 	q.Set("fieldManager", fieldManager)
 	if force {
@@ -107,26 +195,24 @@ func (o *objectAPI[T, PT]) patch(namespace, name, fieldManager string, force boo
 	}
 
 	var t T
-
-	err, resp := o.doAndUnmarshal(&t, client.ResourceRequest{
+	resp, err := o.doAndUnmarshal(ctx, &t, client.ResourceRequest{
 		Verb:        "PATCH",
 		Namespace:   namespace,
 		Name:        name,
 		Values:      q,
-		Body:        bytes.NewReader(s),
 		ContentType: ct,
-	})
+	}, &item)
 
-	return t, err, resp
+	return t, resp, err
 }
 
-func (o *objectAPI[T, PT]) Delete(namespace, name string, force bool) (T, error) {
+func (o *objectAPI[T, PT]) Delete(ctx context.Context, namespace, name string, force bool) (T, error) {
 	q := url.Values{}
 	if force {
 		q.Set("force", "1")
 	}
 
-	return o.doAndUnmarshalItem(client.ResourceRequest{
+	return o.doAndUnmarshalItem(ctx, client.ResourceRequest{
 		Verb:      "DELETE",
 		Namespace: namespace,
 		Name:      name,
@@ -134,11 +220,11 @@ func (o *objectAPI[T, PT]) Delete(namespace, name string, force bool) (T, error)
 	})
 }
 
-func (o *objectAPI[T, PT]) Apply(namespace, name, fieldManager string, force bool, item T) (T, error, types.EventType) {
-	t, resp, err := o.patch(namespace, name, fieldManager, force, client.ApplyPatchContentType, item)
+func (o *objectAPI[T, PT]) Apply(ctx context.Context, namespace, name, fieldManager string, force bool, item T) (T, error, types.EventType) {
+	t, resp, err := o.patch(ctx, namespace, name, fieldManager, force, client.ApplyPatchContentType, item)
 
 	var eventType types.EventType
-	if resp.StatusCode == 201 {
+	if resp != nil && resp.StatusCode == 201 {
 		eventType = types.EventTypeAdded
 	} else {
 		eventType = types.EventTypeModified
@@ -147,41 +233,60 @@ func (o *objectAPI[T, PT]) Apply(namespace, name, fieldManager string, force boo
 	return t, err, eventType
 }
 
-func (o *objectAPI[T, PT]) Patch(namespace, name, fieldManager string, item T) (T, error) {
-	t, _, err := o.patch(namespace, name, fieldManager, false, client.MergePatchContentType, item)
+func (o *objectAPI[T, PT]) Patch(ctx context.Context, namespace, name, fieldManager string, item T) (T, error) {
+	t, _, err := o.patch(ctx, namespace, name, fieldManager, false, client.MergePatchContentType, item)
 	return t, err
 }
 
-func (o *objectAPI[T, PT]) Watch(namespace, name string, opts types.ListOptions) (types.WatchInterface[T, PT], error) {
+func (o *objectAPI[T, PT]) Watch(ctx context.Context, namespace, name string, opts types.ListOptions) (types.WatchInterface[T, PT], error) {
+	return o.WatchWithRelist(ctx, namespace, name, opts, nil)
+}
+
+func (o *objectAPI[T, PT]) WatchWithRelist(ctx context.Context, namespace, name string, opts types.ListOptions, onRelist RelistFunc[T, PT]) (types.WatchInterface[T, PT], error) {
+	// Watching in kubernetes is a collection-level operation so it's not
+	// possible to watch a single resource via its URL. However we can do
+	// it via a fieldSelector on the resource name.
+	if name != "" {
+		opts.FieldSelector = append(slices.Clip(opts.FieldSelector), types.FieldSelector{
+			Field: "metadata.name",
+			Value: name,
+		})
+	}
+
 	req := client.ResourceRequest{
 		Namespace: namespace,
-		Values:    make(url.Values, len(opts.LabelSelector)+1),
+		Values:    selectorValues(opts),
 		GVR:       o.gvr,
 	}
 	req.Values.Set("watch", "1")
-	for _, label := range opts.LabelSelector {
-		if label.Operator == types.Exists {
-			req.Values.Add("labelSelector", label.Label)
-		} else {
-			req.Values.Add("labelSelector", label.Label+label.Operator+label.Value)
-		}
+	if opts.AllowWatchBookmarks || opts.SendInitialEvents {
+		req.Values.Set("allowWatchBookmarks", "true")
+	}
+	if opts.SendInitialEvents {
+		req.Values.Set("sendInitialEvents", "true")
+		req.Values.Set("resourceVersionMatch", "NotOlderThan")
 	}
 
-	// Watching in kubernetes is a collection-level operation so it's not
-	// possible to watch a single resource via its URL. However we can do
-	// it via a fieldSelector on the resource name.
-	if name != "" {
-		req.Values.Add("fieldSelector", "metadata.name"+types.Equals+name)
+	listOpts := types.ListOptions{
+		LabelSelector: opts.LabelSelector,
+		FieldSelector: opts.FieldSelector,
 	}
 
 	watch := &Watcher[T, PT]{
+		ctx:             ctx,
 		req:             req,
 		api:             o.kc,
+		codecs:          o.codecs,
 		resourceVersion: opts.ResourceVersion,
+		relist: func() ([]T, string, error) {
+			return listAll(ctx, o, namespace, listOpts)
+		},
+		onRelist:      onRelist,
+		initialEvents: opts.SendInitialEvents,
 	}
-	if err := watch.doWatch(); err != nil {
+	if err := watch.watch(); err != nil {
 		return nil, err
 	}
 
-	return stream.NewAsyncStream[types.Event[T, PT]](watch), nil
+	return stream.NewAsyncStream[types.Event[T, PT]](ctx, watch), nil
 }
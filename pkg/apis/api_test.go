@@ -0,0 +1,87 @@
+package apis
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/EmilyShepherd/k8s-client-go/pkg/client"
+	"github.com/EmilyShepherd/k8s-client-go/types"
+)
+
+func TestListSendsSelectors(t *testing.T) {
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[]}`))
+	}))
+	defer srv.Close()
+
+	kc, err := client.NewClient(srv.URL, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := NewObjectAPI[corev1.Pod](kc, types.GroupVersionResource{Version: "v1", Resource: "pods"})
+
+	_, err = api.List(context.Background(), "default", types.ListOptions{
+		LabelSelector: []types.LabelSelector{
+			{Label: "app", Value: "x", Operator: types.Equals},
+			{Label: "tier", Operator: types.Exists},
+		},
+		FieldSelector: []types.FieldSelector{
+			{Field: "spec.nodeName", Value: "node1"},
+		},
+		Limit: 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := url.Values{
+		"labelSelector": {"app=x,tier"},
+		"fieldSelector": {"spec.nodeName=node1"},
+		"limit":         {"10"},
+	}
+	if query.Encode() != want.Encode() {
+		t.Errorf("List sent query %q, want %q", query.Encode(), want.Encode())
+	}
+}
+
+func TestFieldMatchMissingFields(t *testing.T) {
+	pod := &corev1.Pod{}
+	pod.Name = "a"
+	pod.Status.Phase = corev1.PodRunning
+
+	unstructured := map[string]any{
+		"metadata": map[string]any{"name": "a"},
+	}
+
+	tests := []struct {
+		name     string
+		obj      any
+		selector types.FieldSelector
+		want     bool
+	}{
+		{"pod name", pod, types.FieldSelector{Field: "metadata.name", Value: "a"}, true},
+		{"pod phase", pod, types.FieldSelector{Field: "status.phase", Value: "Running"}, true},
+		{"pod empty node", pod, types.FieldSelector{Field: "spec.nodeName", Value: "foo", Operator: types.NotEquals}, true},
+		{"pod other field", pod, types.FieldSelector{Field: "spec.dnsPolicy", Value: ""}, true},
+		{"pod non-scalar", pod, types.FieldSelector{Field: "spec.containers", Value: ""}, false},
+		{"missing object", unstructured, types.FieldSelector{Field: "spec.nodeName", Value: "foo", Operator: types.NotEquals}, true},
+		{"missing object equals", unstructured, types.FieldSelector{Field: "spec.nodeName", Value: ""}, true},
+		{"unstructured name", unstructured, types.FieldSelector{Field: "metadata.name", Value: "b"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FieldMatch([]types.FieldSelector{tt.selector}, tt.obj); got != tt.want {
+				t.Errorf("FieldMatch(%v) = %v, want %v", tt.selector, got, tt.want)
+			}
+		})
+	}
+}
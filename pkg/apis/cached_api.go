@@ -1,8 +1,11 @@
 package apis
 
 import (
-	"fmt"
+	"context"
+	"maps"
+	"slices"
 
+	"github.com/EmilyShepherd/k8s-client-go/pkg/client"
 	"github.com/EmilyShepherd/k8s-client-go/pkg/util"
 	"github.com/EmilyShepherd/k8s-client-go/types"
 )
@@ -10,15 +13,29 @@ import (
 var start int64
 
 type CachedAPI[T any, PT types.Object[T]] struct {
-	api   types.ObjectAPI[T, PT]
-	cache *ResourceCache[T, PT]
+	api      types.ObjectAPI[T, PT]
+	cache    *ResourceCache[T, PT]
+	resource string
 }
 
-func NewCachedAPI[T any, PT types.Object[T]](rawApi types.ObjectAPI[T, PT], namespace string, opts types.ListOptions) (*CachedAPI[T, PT], error) {
-	cache, err := NewResourceCache(rawApi, namespace, opts)
+// resourcer is implemented by ObjectAPIs which know which resource they
+// are for, so that CachedAPI can report it in NotFound errors.
+type resourcer interface {
+	resource() string
+}
+
+func NewCachedAPI[T any, PT types.Object[T]](ctx context.Context, rawApi types.ObjectAPI[T, PT], namespace string, opts types.ListOptions) (*CachedAPI[T, PT], error) {
+	cache, err := NewResourceCache(ctx, rawApi, namespace, opts)
+
+	resource := "object"
+	if r, ok := rawApi.(resourcer); ok {
+		resource = r.resource()
+	}
+
 	return &CachedAPI[T, PT]{
-		api:   rawApi,
-		cache: cache,
+		api:      rawApi,
+		cache:    cache,
+		resource: resource,
 	}, err
 }
 
@@ -26,66 +43,88 @@ func (i *CachedAPI[T, PT]) Cache() *ResourceCache[T, PT] {
 	return i.cache
 }
 
-func (i *CachedAPI[T, PT]) Watch(name, namespace string, opts types.ListOptions) (types.WatchInterface[T, PT], error) {
+// Watch the items in the cached collection. As with List, any namespace
+// and selectors are matched against client side.
+func (i *CachedAPI[T, PT]) Watch(ctx context.Context, namespace, name string, opts types.ListOptions) (types.WatchInterface[T, PT], error) {
+	if name != "" {
+		opts.FieldSelector = append(slices.Clip(opts.FieldSelector), types.FieldSelector{
+			Field: "metadata.name",
+			Value: name,
+		})
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
 	p := pipeWatcher[T, PT]{
+		ctx:       ctx,
+		cancel:    cancel,
 		result:    make(chan types.Event[T, PT]),
 		namespace: namespace,
-		selectors: opts.LabelSelector,
+		opts:      opts,
 	}
 
 	go i.cache.RegisterListener(&p)
 
+	// The pipe is only fed by the cache, so the only thing we need to do
+	// when the context ends is stop it.
+	go func() {
+		<-ctx.Done()
+		p.Stop()
+	}()
+
 	return &p, nil
 }
 
 // Returns an item in the cached collection
-func (i *CachedAPI[T, PT]) Get(namespace, name string, opts types.GetOptions) (T, error) {
-	key := util.GetKey(namespace, name)
-	item, found := i.cache.Get(key)
+func (i *CachedAPI[T, PT]) Get(ctx context.Context, namespace, name string, opts types.GetOptions) (T, error) {
+	item, found := i.cache.Get(util.GetKey(namespace, name))
 	if !found {
-		return item, fmt.Errorf("Could not find object %s", key)
+		return item, client.NewNotFound(i.resource, name)
 	}
 
 	return item, nil
 }
 
 // List the items in the cached collection.
-// If a namespace or LabelSelectors are provided, these will be matched
-// against client side.
-func (i *CachedAPI[T, PT]) List(namespace string, opts types.ListOptions) (*types.List[T, PT], error) {
-	list := types.List[T, PT]{}
-
+// If a namespace, LabelSelectors or FieldSelectors are provided, these
+// will be matched against client side.
+func (i *CachedAPI[T, PT]) List(ctx context.Context, namespace string, opts types.ListOptions) (*types.List[T, PT], error) {
+	// The items are matched after releasing the lock, so that the cache
+	// isn't blocked while we do so.
 	i.cache.itemLock.RLock()
-	for _, item := range i.cache.items {
-		if Matches(namespace, opts.LabelSelector, PT(&item)) {
+	items := slices.Collect(maps.Values(i.cache.items))
+	i.cache.itemLock.RUnlock()
+
+	list := types.List[T, PT]{}
+	for _, item := range items {
+		if Matches(namespace, opts, PT(&item)) {
 			list.Items = append(list.Items, item)
 		}
 	}
-	i.cache.itemLock.RUnlock()
 
 	return &list, nil
 }
 
-func (i *CachedAPI[T, PT]) Delete(namespace, name string, force bool) (T, error) {
-	return i.api.Delete(namespace, name, force)
+func (i *CachedAPI[T, PT]) Delete(ctx context.Context, namespace, name string, force bool) (T, error) {
+	return i.api.Delete(ctx, namespace, name, force)
 }
 
-func (i *CachedAPI[T, PT]) Create(namespace string, item T) (T, error) {
-	return i.api.Create(namespace, item)
+func (i *CachedAPI[T, PT]) Create(ctx context.Context, namespace string, item T) (T, error) {
+	return i.api.Create(ctx, namespace, item)
 }
 
-func (i *CachedAPI[T, PT]) Apply(namespace, name, fieldManager string, force bool, item T) (T, error, types.EventType) {
-	return i.api.Apply(namespace, name, fieldManager, force, item)
+func (i *CachedAPI[T, PT]) Apply(ctx context.Context, namespace, name, fieldManager string, force bool, item T) (T, error, types.EventType) {
+	return i.api.Apply(ctx, namespace, name, fieldManager, force, item)
 }
 
-func (i *CachedAPI[T, PT]) Patch(namespace, name, fieldManager string, item T) (T, error) {
-	return i.api.Patch(namespace, name, fieldManager, item)
+func (i *CachedAPI[T, PT]) Patch(ctx context.Context, namespace, name, fieldManager string, item T) (T, error) {
+	return i.api.Patch(ctx, namespace, name, fieldManager, item)
 }
 
 func (o *CachedAPI[T, PT]) Subresource(subresource string) types.ObjectAPI[T, PT] {
 	return &CachedAPI[T, PT]{
-		api:   o.api.Subresource(subresource),
-		cache: o.cache,
+		api:      o.api.Subresource(subresource),
+		cache:    o.cache,
+		resource: o.resource,
 	}
 }
 
@@ -1,24 +1,94 @@
 package apis
 
 import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+
 	"github.com/EmilyShepherd/k8s-client-go/types"
 )
 
-func Matches[T any, PT types.Object[T]](namespace string, selectors []types.LabelSelector, item PT) bool {
+// Matches returns true if the item is in the namespace, if one is given,
+// and matches the label and field selectors in opts, in the same way as
+// the apiserver would.
+func Matches[T any, PT types.Object[T]](namespace string, opts types.ListOptions, item PT) bool {
 	if namespace != "" && namespace != item.GetNamespace() {
 		return false
 	}
-	return LabelMatch(selectors, item.GetLabels())
+	return LabelMatch(opts.LabelSelector, item.GetLabels()) && FieldMatch(opts.FieldSelector, item)
 }
 
+// LabelMatch returns true if the labels match all of the selectors, with
+// the same semantics as the apiserver. In particular, NotEquals, NotIn
+// and DoesNotExist match objects which don't have the label at all, and
+// LessThan and GreaterThan only match labels with integer values.
 func LabelMatch(selectors []types.LabelSelector, labels map[string]string) bool {
 	for _, selector := range selectors {
-		value, exists := labels[selector.Label]
+		if !labelMatchOne(selector, labels) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func labelMatchOne(selector types.LabelSelector, labels map[string]string) bool {
+	value, exists := labels[selector.Label]
+
+	switch selector.Operator {
+	case "", types.Equals, "==":
+		return exists && value == selector.Value
+	case types.NotEquals:
+		return !exists || value != selector.Value
+	case types.In:
+		return exists && slices.Contains(selector.Values, value)
+	case types.NotIn:
+		return !exists || !slices.Contains(selector.Values, value)
+	case types.Exists:
+		return exists
+	case types.DoesNotExist:
+		return !exists
+	case types.LessThan, types.GreaterThan:
 		if !exists {
 			return false
 		}
+		have, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false
+		}
+		want, err := strconv.ParseInt(selector.Value, 10, 64)
+		if err != nil {
+			return false
+		}
+		if selector.Operator == types.LessThan {
+			return have < want
+		}
+		return have > want
+	}
+
+	// An unknown operator can never match, as the apiserver would reject
+	// it.
+	return false
+}
+
+// FieldMatch returns true if the object matches all of the field
+// selectors. Fields are looked up by their path in the object's JSON
+// encoding, and missing fields, or fields inside missing objects, are
+// treated as empty, as the apiserver does. Fields which are not scalar
+// values never match.
+func FieldMatch(selectors []types.FieldSelector, obj any) bool {
+	for _, selector := range selectors {
+		value, ok := fieldValue(obj, selector.Field)
+		if !ok {
+			return false
+		}
+
 		switch selector.Operator {
-		case types.Equals:
+		case "", types.Equals, "==":
 			if value != selector.Value {
 				return false
 			}
@@ -26,16 +96,169 @@ func LabelMatch(selectors []types.LabelSelector, labels map[string]string) bool
 			if value == selector.Value {
 				return false
 			}
-		case types.LessThan:
-			if value >= selector.Value {
-				return false
-			}
-		case types.GreaterThan:
-			if value <= selector.Value {
-				return false
-			}
+		default:
+			return false
 		}
 	}
 
 	return true
 }
+
+// fieldValue looks up the field at the given dotted path, returning its
+// value as a string. It returns false if the field is not a scalar.
+//
+// The fields which the apiserver supports for most resources are read
+// directly, and anything else is found by reflection.
+func fieldValue(obj any, path string) (string, bool) {
+	switch path {
+	case "metadata.name":
+		if o, ok := obj.(interface{ GetName() string }); ok {
+			return o.GetName(), true
+		}
+	case "metadata.namespace":
+		if o, ok := obj.(interface{ GetNamespace() string }); ok {
+			return o.GetNamespace(), true
+		}
+	}
+
+	if pod, ok := obj.(*corev1.Pod); ok {
+		if value, ok := podFieldValue(pod, path); ok {
+			return value, true
+		}
+	}
+
+	value := reflect.ValueOf(obj)
+	for _, key := range strings.Split(path, ".") {
+		value = fieldByJSONName(indirect(value), key)
+		if !value.IsValid() {
+			return "", true
+		}
+	}
+
+	return scalarString(indirect(value))
+}
+
+// podFieldValue reads the fields which the apiserver supports in field
+// selectors for pods, which are by far the most commonly selected.
+func podFieldValue(pod *corev1.Pod, path string) (string, bool) {
+	switch path {
+	case "spec.nodeName":
+		return pod.Spec.NodeName, true
+	case "spec.restartPolicy":
+		return string(pod.Spec.RestartPolicy), true
+	case "spec.schedulerName":
+		return pod.Spec.SchedulerName, true
+	case "spec.serviceAccountName":
+		return pod.Spec.ServiceAccountName, true
+	case "spec.hostNetwork":
+		return strconv.FormatBool(pod.Spec.HostNetwork), true
+	case "status.phase":
+		return string(pod.Status.Phase), true
+	case "status.podIP":
+		return pod.Status.PodIP, true
+	case "status.nominatedNodeName":
+		return pod.Status.NominatedNodeName, true
+	}
+
+	return "", false
+}
+
+// indirect follows pointers and interfaces to the value they hold. It
+// returns the zero Value if any of them are nil.
+func indirect(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+
+	return value
+}
+
+// fieldByJSONName returns the field of a struct, or the entry of a map,
+// with the given JSON name. It returns the zero Value if there is none.
+func fieldByJSONName(value reflect.Value, name string) reflect.Value {
+	switch value.Kind() {
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}
+		}
+		return value.MapIndex(reflect.ValueOf(name).Convert(value.Type().Key()))
+	case reflect.Struct:
+		index, ok := jsonFieldIndex(value.Type(), name)
+		if !ok {
+			return reflect.Value{}
+		}
+		field, err := value.FieldByIndexErr(index)
+		if err != nil {
+			return reflect.Value{}
+		}
+		return field
+	}
+
+	return reflect.Value{}
+}
+
+type jsonFieldKey struct {
+	t    reflect.Type
+	name string
+}
+
+// jsonFieldIndices caches the index of each struct field by its JSON
+// name, as finding it means walking every field of the struct.
+var jsonFieldIndices sync.Map
+
+func jsonFieldIndex(t reflect.Type, name string) ([]int, bool) {
+	key := jsonFieldKey{t, name}
+	if cached, ok := jsonFieldIndices.Load(key); ok {
+		index := cached.([]int)
+		return index, index != nil
+	}
+
+	var index []int
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() {
+			continue
+		}
+
+		tagName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if field.Anonymous && tagName == "" {
+			// Embedded structs are inlined, and their fields are visited
+			// separately.
+			continue
+		}
+		if tagName == "" {
+			tagName = field.Name
+		}
+		if tagName == name {
+			index = field.Index
+			break
+		}
+	}
+
+	jsonFieldIndices.Store(key, index)
+
+	return index, index != nil
+}
+
+// scalarString formats a scalar value as the apiserver would in a field
+// selector. It returns false if the value is not a scalar.
+func scalarString(value reflect.Value) (string, bool) {
+	switch value.Kind() {
+	case reflect.Invalid:
+		return "", true
+	case reflect.String:
+		return value.String(), true
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64), true
+	}
+
+	return "", false
+}
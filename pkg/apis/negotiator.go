@@ -0,0 +1,47 @@
+package apis
+
+import (
+	"sync/atomic"
+
+	"github.com/EmilyShepherd/k8s-client-go/pkg/client"
+	"github.com/EmilyShepherd/k8s-client-go/pkg/codec"
+)
+
+// negotiator holds the codecs which an ObjectAPI asks the apiserver for,
+// in order of preference, with JSON last. If the apiserver rejects the
+// preferred codec, with a 406 or 415, JSON is used from then on.
+type negotiator struct {
+	codecs   []codec.Codec
+	jsonOnly atomic.Bool
+}
+
+func newNegotiator(preferred, json codec.Codec) *negotiator {
+	n := &negotiator{codecs: []codec.Codec{json}}
+	if preferred != nil && preferred.MediaType() != json.MediaType() {
+		n.codecs = append([]codec.Codec{preferred}, n.codecs...)
+	}
+
+	return n
+}
+
+// Codecs returns the codecs to use for the next request
+func (n *negotiator) Codecs() []codec.Codec {
+	if n.jsonOnly.Load() {
+		return n.codecs[len(n.codecs)-1:]
+	}
+
+	return n.codecs
+}
+
+// fallback checks whether a request made with the given codecs failed
+// because the apiserver doesn't support the preferred one. If so, it
+// switches to JSON and returns true, so that the request can be tried
+// again.
+func (n *negotiator) fallback(used []codec.Codec, err error) bool {
+	if len(used) == 1 || (!client.IsNotAcceptable(err) && !client.IsUnsupportedMediaType(err)) {
+		return false
+	}
+
+	n.jsonOnly.Store(true)
+	return true
+}
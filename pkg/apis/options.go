@@ -0,0 +1,65 @@
+package apis
+
+import (
+	"io"
+
+	"github.com/EmilyShepherd/k8s-client-go/pkg/codec"
+	"github.com/EmilyShepherd/k8s-client-go/pkg/stream"
+)
+
+// Option configures optional behaviour of an ObjectAPI when passed to
+// NewObjectAPI.
+type Option func(*apiOptions)
+
+type apiOptions struct {
+	codec codec.Codec
+	json  codec.JSONOptions
+}
+
+// WithCodec sets the wire format which the ObjectAPI asks the apiserver
+// for, such as [codec.Protobuf] or [codec.CBOR]. JSON is always accepted
+// as well, so that resources which don't support the codec, such as CRDs
+// with protobuf, still work. If the object type itself isn't supported by
+// the codec, it is ignored.
+//
+// New objects and apply patches are also encoded with the codec where
+// possible, falling back to JSON otherwise. Merge patches are always sent
+// as JSON. If the apiserver rejects the codec, with a 406 or 415, the
+// ObjectAPI uses JSON from then on.
+func WithCodec(c codec.Codec) Option {
+	return func(o *apiOptions) {
+		o.codec = c
+	}
+}
+
+// WithDecoder sets the factory for the decoders used for JSON responses,
+// including watch streams, in place of encoding/json. This allows faster
+// JSON libraries, such as sonic, jsoniter or goccy/go-json, to be used.
+func WithDecoder(f ResponseDecoderFunc) Option {
+	return func(o *apiOptions) {
+		o.json.NewDecoder = func(r io.Reader) stream.Decoder {
+			return f(r)
+		}
+	}
+}
+
+// EncoderFunc encodes a request body as JSON
+type EncoderFunc func(v any) ([]byte, error)
+
+// WithEncoder sets the function used to encode JSON request bodies, for
+// Create, Apply and Patch, in place of json.Marshal.
+func WithEncoder(f EncoderFunc) Option {
+	return func(o *apiOptions) {
+		o.json.Marshal = f
+	}
+}
+
+// WithStrict makes decoding JSON responses fail if they have fields
+// which the object type does not, rather than silently dropping them. If
+// a decoder is set with WithDecoder, it must have a DisallowUnknownFields
+// method.
+func WithStrict() Option {
+	return func(o *apiOptions) {
+		o.json.DisallowUnknownFields = true
+	}
+}
@@ -0,0 +1,62 @@
+package apis
+
+import (
+	"context"
+	"iter"
+
+	"github.com/EmilyShepherd/k8s-client-go/types"
+)
+
+// DefaultPageSize is the number of items fetched per List call by Pages
+// and Items, if opts.Limit isn't set.
+const DefaultPageSize = 500
+
+// Pages lists the collection a page at a time, fetching each page only
+// once the previous one has been consumed.
+//
+// If the collection changes so much while it is being listed that the
+// continue token expires, the apiserver's 410 error is yielded, and the
+// iteration stops. The caller can either start again or, if
+// [client.InconsistentContinue] returns a token, set it as opts.Continue
+// to carry on from a newer snapshot.
+func Pages[T any, PT types.Object[T]](ctx context.Context, api types.ObjectAPI[T, PT], namespace string, opts types.ListOptions) iter.Seq2[*types.List[T, PT], error] {
+	if opts.Limit == 0 {
+		opts.Limit = DefaultPageSize
+	}
+
+	return func(yield func(*types.List[T, PT], error) bool) {
+		for {
+			list, err := api.List(ctx, namespace, opts)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(list, nil) || list.Continue == "" {
+				return
+			}
+			opts.Continue = list.Continue
+		}
+	}
+}
+
+// Items lists every item in the collection, paging through it with
+// Pages. If an error occurs, it is yielded with a zero item and the
+// iteration stops.
+func Items[T any, PT types.Object[T]](ctx context.Context, api types.ObjectAPI[T, PT], namespace string, opts types.ListOptions) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for list, err := range Pages(ctx, api, namespace, opts) {
+			if err != nil {
+				var t T
+				yield(t, err)
+				return
+			}
+
+			for _, item := range list.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
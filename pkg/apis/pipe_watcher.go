@@ -1,23 +1,52 @@
 package apis
 
 import (
+	"context"
+	"sync"
+
 	"github.com/EmilyShepherd/k8s-client-go/types"
 )
 
 type pipeWatcher[T any, PT types.Object[T]] struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	lock      sync.Mutex
+	stopped   bool
 	result    chan types.Event[T, PT]
 	namespace string
-	selectors []types.LabelSelector
+	opts      types.ListOptions
 }
 
 func (p *pipeWatcher[T, PT]) Event(event types.Event[T, PT]) {
-	if Matches(p.namespace, p.selectors, PT(&event.Object)) {
-		p.result <- event
+	if !Matches(p.namespace, p.opts, PT(&event.Object)) {
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.stopped {
+		return
+	}
+
+	select {
+	case p.result <- event:
+	case <-p.ctx.Done():
 	}
 }
 
 func (p *pipeWatcher[T, PT]) Stop() {
-	close(p.result)
+	// Cancelling first unblocks any Event() which is waiting for the
+	// reader, so that we can safely take the lock.
+	p.cancel()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if !p.stopped {
+		p.stopped = true
+		close(p.result)
+	}
 }
 
 func (p *pipeWatcher[T, PT]) ResultChan() <-chan types.Event[T, PT] {
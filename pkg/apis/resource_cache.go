@@ -1,8 +1,11 @@
 package apis
 
 import (
+	"context"
 	"sync"
+	"sync/atomic"
 
+	"github.com/EmilyShepherd/k8s-client-go/pkg/client"
 	"github.com/EmilyShepherd/k8s-client-go/pkg/util"
 	"github.com/EmilyShepherd/k8s-client-go/types"
 )
@@ -13,59 +16,148 @@ type EventListener[T any, PT types.Object[T]] interface {
 }
 
 type ResourceCache[T any, PT types.Object[T]] struct {
+	ctx      context.Context
 	watcher  types.WatchInterface[T, PT]
 	items    map[string]T
 	watchers []EventListener[T, PT]
 	itemLock sync.RWMutex
-	ready    bool
+	ready    atomic.Bool
+
+	// relists holds the lists from the watch relisting, until they are
+	// reconciled by processEvent.
+	relistLock sync.Mutex
+	relists    [][]T
 }
 
-func NewResourceCache[T any, PT types.Object[T]](rawApi types.ObjectAPI[T, PT], namespace string, opts types.ListOptions) (*ResourceCache[T, PT], error) {
+// NewResourceCache lists the matching objects and then watches them for
+// changes for as long as ctx remains active.
+//
+// The objects are listed in pages of opts.Limit, or DefaultPageSize if
+// it isn't set, to avoid fetching large collections in one response.
+//
+// If opts.SendInitialEvents is set, the objects are instead streamed as
+// the initial events of the watch, which is much cheaper for the
+// apiserver. The cache is ready once the last of them has arrived, which
+// NewResourceCache waits for. If the apiserver doesn't support this, the
+// Watcher falls back to listing the objects.
+func NewResourceCache[T any, PT types.Object[T]](ctx context.Context, rawApi types.ObjectAPI[T, PT], namespace string, opts types.ListOptions) (*ResourceCache[T, PT], error) {
 	api := ResourceCache[T, PT]{
+		ctx:   ctx,
 		items: make(map[string]T),
 	}
 
-	list, err := rawApi.List(namespace, opts)
-	if err != nil {
-		return nil, err
-	}
+	relister, canRelist := rawApi.(RelistWatcher[T, PT])
+	streaming := opts.SendInitialEvents && canRelist
 
-	for _, item := range list.Items {
-		api.items[util.GetKeyForObject[T, PT](&item)] = item
+	if !streaming {
+		items, resourceVersion, err := listAll(ctx, rawApi, namespace, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range items {
+			api.items[util.GetKeyForObject[T, PT](&item)] = item
+		}
+
+		opts.ResourceVersion = resourceVersion
+		opts.SendInitialEvents = false
 	}
-	opts.ResourceVersion = list.ResourceVersion
 
-	watcher, err := rawApi.Watch(namespace, "", opts)
+	opts.Limit = 0
+	opts.Continue = ""
+	opts.AllowWatchBookmarks = true
+
+	// If the watch's resourceVersion expires, it has to relist, and we
+	// need to reconcile our items with the new list.
+	var watcher types.WatchInterface[T, PT]
+	var err error
+	if canRelist {
+		watcher, err = relister.WatchWithRelist(ctx, namespace, "", opts, api.relisted)
+	} else {
+		watcher, err = rawApi.Watch(ctx, namespace, "", opts)
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	api.watcher = watcher
 
+	synced := make(chan struct{})
+	failed := make(chan error, 1)
+	if !streaming {
+		api.ready.Store(true)
+		close(synced)
+	}
+
 	go func() {
+		waiting := streaming
 		for {
 			result, err := api.watcher.Next()
 			if err == nil {
+				if waiting && result.IsInitialEventsEnd() {
+					waiting = false
+					api.ready.Store(true)
+					close(synced)
+				}
 				api.processEvent(result)
 			} else {
-				api.ready = false
+				api.ready.Store(false)
 
 				for _, watcher := range api.watchers {
 					watcher.Stop()
 				}
 
+				failed <- err
 				return
 			}
 		}
 	}()
 
-	api.ready = true
+	select {
+	case <-synced:
+	case err := <-failed:
+		return nil, err
+	}
 
 	return &api, nil
 }
 
+// listAll pages through the whole collection, returning its items and
+// the resourceVersion they were listed at. If the continue token expires
+// part way through, the list is started again, as a consistent snapshot
+// is needed to start watching from.
+func listAll[T any, PT types.Object[T]](ctx context.Context, rawApi types.ObjectAPI[T, PT], namespace string, opts types.ListOptions) ([]T, string, error) {
+	for {
+		var items []T
+		var resourceVersion string
+
+		var err error
+		for list, pageErr := range Pages(ctx, rawApi, namespace, opts) {
+			if err = pageErr; err != nil {
+				break
+			}
+
+			items = append(items, list.Items...)
+			resourceVersion = list.ResourceVersion
+		}
+
+		if client.IsResourceExpired(err) && opts.Continue == "" {
+			continue
+		}
+
+		return items, resourceVersion, err
+	}
+}
+
+// IsReady returns true once the cache holds the full collection, until
+// its watch fails.
 func (i *ResourceCache[T, PT]) IsReady() bool {
-	return i.ready
+	return i.ready.Load()
+}
+
+// Context returns the context which the cache's watch runs in
+func (i *ResourceCache[T, PT]) Context() context.Context {
+	return i.ctx
 }
 
 func (i *ResourceCache[T, PT]) Error() error {
@@ -93,7 +185,59 @@ func (i *ResourceCache[T, PT]) RegisterListener(listener EventListener[T, PT]) {
 	i.watchers = append(i.watchers, listener)
 }
 
+// relisted is called by the watch after it has had to relist. Events
+// from before the relist may still be on their way to processEvent, so
+// rather than reconciling the list with our items now, it is queued, and
+// a bookmark is returned for processEvent to reconcile it at, once they
+// have all been applied.
+func (i *ResourceCache[T, PT]) relisted(items []T) []types.Event[T, PT] {
+	i.relistLock.Lock()
+	i.relists = append(i.relists, items)
+	i.relistLock.Unlock()
+
+	return []types.Event[T, PT]{{Type: types.EventTypeBookmark}}
+}
+
+// reconcile compares the cache's items with a fresh list of them, after
+// the watch had to relist, and returns the events which bring the cache,
+// and its listeners, up to date.
+func (i *ResourceCache[T, PT]) reconcile(items []T) []types.Event[T, PT] {
+	i.itemLock.RLock()
+	defer i.itemLock.RUnlock()
+
+	var events []types.Event[T, PT]
+	listed := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		key := util.GetKeyForObject[T, PT](&item)
+		listed[key] = struct{}{}
+
+		existing, found := i.items[key]
+		if !found {
+			events = append(events, types.Event[T, PT]{Type: types.EventTypeAdded, Object: item})
+		} else if PT(&existing).GetResourceVersion() != PT(&item).GetResourceVersion() {
+			events = append(events, types.Event[T, PT]{Type: types.EventTypeModified, Object: item})
+		}
+	}
+
+	for key, item := range i.items {
+		if _, found := listed[key]; !found {
+			events = append(events, types.Event[T, PT]{Type: types.EventTypeDeleted, Object: item})
+		}
+	}
+
+	return events
+}
+
 func (i *ResourceCache[T, PT]) processEvent(e types.Event[T, PT]) {
+	// Bookmarks carry no object, so there is nothing to cache or pass on,
+	// but one may mark the point at which a relist is to be reconciled.
+	if e.Type == types.EventTypeBookmark {
+		if !e.IsInitialEventsEnd() {
+			i.reconcileRelist()
+		}
+		return
+	}
+
 	key := util.GetKeyForObject[T, PT](&e.Object)
 
 	i.itemLock.Lock()
@@ -108,3 +252,20 @@ func (i *ResourceCache[T, PT]) processEvent(e types.Event[T, PT]) {
 		watcher.Event(e)
 	}
 }
+
+// reconcileRelist reconciles the oldest queued relist, if there is one,
+// with our items, and applies the resulting events.
+func (i *ResourceCache[T, PT]) reconcileRelist() {
+	i.relistLock.Lock()
+	if len(i.relists) == 0 {
+		i.relistLock.Unlock()
+		return
+	}
+	items := i.relists[0]
+	i.relists = i.relists[1:]
+	i.relistLock.Unlock()
+
+	for _, e := range i.reconcile(items) {
+		i.processEvent(e)
+	}
+}
@@ -1,35 +1,83 @@
 package apis
 
 import (
-	"encoding/json"
+	"context"
+	"fmt"
 	"io"
+	"sync/atomic"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/EmilyShepherd/k8s-client-go/pkg/client"
+	"github.com/EmilyShepherd/k8s-client-go/pkg/codec"
+	"github.com/EmilyShepherd/k8s-client-go/pkg/stream"
 	"github.com/EmilyShepherd/k8s-client-go/types"
 )
 
 // ResponseDecoder allows to specify custom JSON response decoder. By default, std json decoder is used.
+// See [WithDecoder].
 type ResponseDecoder interface {
 	Decode(v any) error
 }
 
+// RelistFunc is given the items from a fresh List of a watched
+// collection, after the watch's resourceVersion has expired, and returns
+// the events to send to the watcher in place of the ones it missed.
+type RelistFunc[T any, PT types.Object[T]] func(items []T) []types.Event[T, PT]
+
+// RelistWatcher is implemented by ObjectAPIs whose watches can recover
+// from their resourceVersion expiring, by listing the collection again
+// and carrying on from there. ResourceCache uses it to reconcile its
+// items with the fresh list.
+type RelistWatcher[T any, PT types.Object[T]] interface {
+	// WatchWithRelist is the same as Watch, except that onRelist decides
+	// which events are sent after a relist. If it is nil, an ADDED event
+	// is sent for each listed item.
+	WatchWithRelist(ctx context.Context, namespace, name string, opts types.ListOptions, onRelist RelistFunc[T, PT]) (types.WatchInterface[T, PT], error)
+}
+
 // Watcher is a [Stream] wrapper for kubernetes watch events.
 
 // In addition to processing objects as [watch.Event] structs, the
 // Watcher will also keep track of the latest resourceVersion returned
 // and will attempt to gracefully reconnect when watch connections
 // time out.
+//
+// BOOKMARK events, if they are enabled by AllowWatchBookmarks, are only
+// used to update the resourceVersion, and are not returned from Next,
+// apart from the one which marks the end of the initial events of a
+// watch with SendInitialEvents. If the apiserver doesn't support
+// SendInitialEvents, the Watcher lists the collection instead, and sends
+// the same events as it would have.
+//
+// ERROR events are returned from Next as a [client.StatusError]. If the
+// error is because the resourceVersion is too old, the Watcher instead
+// lists the collection again and restarts the watch from there.
 type Watcher[T any, PT types.Object[T]] struct {
+	ctx             context.Context
 	closer          io.Closer
-	decoder         ResponseDecoder
+	decoder         stream.Decoder
+	codec           codec.Codec
+	codecs          *negotiator
 	api             *client.Client
 	req             client.ResourceRequest
 	resourceVersion string
+
+	relist   func() ([]T, string, error)
+	onRelist RelistFunc[T, PT]
+	pending  []types.Event[T, PT]
+
+	// closed is set by Close, which may be called while Next is running
+	closed atomic.Bool
+
+	// initialEvents is set while waiting for the end of the initial events
+	// of a watch with SendInitialEvents.
+	initialEvents bool
 }
 
 // [Close] closes the underlying response body io.ReadCloser
 func (sw *Watcher[T, PT]) Close() error {
-	sw.decoder = nil
+	sw.closed.Store(true)
 
 	return sw.closer.Close()
 }
@@ -39,41 +87,189 @@ func (sw *Watcher[T, PT]) doWatch() error {
 		sw.req.Values.Set("resourceVersion", sw.resourceVersion)
 	}
 
-	resp, err := sw.api.Do(sw.req)
+	codecs := sw.codecs.Codecs()
+	sw.req.Accept = codec.Accept(true, codecs...)
+	resp, err := sw.api.Do(sw.ctx, sw.req)
 	if err != nil {
+		if sw.codecs.fallback(codecs, err) {
+			return sw.doWatch()
+		}
 		return err
 	}
 
+	// The apiserver replies in JSON, rather than our preferred codec, for
+	// resources which don't support it.
+	sw.codec = codec.ForContentType(resp.Header.Get("Content-Type"), codecs...)
 	sw.closer = resp.Body
-	sw.decoder = json.NewDecoder(resp.Body)
+	sw.decoder = sw.codec.NewWatchDecoder(resp.Body)
 
 	return nil
 }
 
+// watch starts the watch, relisting first if the resourceVersion has
+// already expired, or if the apiserver rejects SendInitialEvents.
+func (sw *Watcher[T, PT]) watch() error {
+	err := sw.doWatch()
+	if client.IsGone(err) {
+		return sw.relistAndWatch(err)
+	}
+
+	// Apiservers without the WatchList feature reject resourceVersionMatch
+	// on a watch, so we fall back to listing.
+	if sw.initialEvents && (client.IsBadRequest(err) || client.IsInvalid(err)) {
+		return sw.relistAndWatch(err)
+	}
+
+	return err
+}
+
+// endInitialEvents stops asking for initial events, once they have all
+// been received, so that a reconnect carries on from our resourceVersion.
+func (sw *Watcher[T, PT]) endInitialEvents() {
+	sw.initialEvents = false
+	sw.req.Values.Del("sendInitialEvents")
+	sw.req.Values.Del("resourceVersionMatch")
+}
+
+// initialEventsEnd builds a bookmark marking the end of the initial
+// events, for when they have been listed rather than streamed.
+func (sw *Watcher[T, PT]) initialEventsEnd() types.Event[T, PT] {
+	evt := types.Event[T, PT]{Type: types.EventTypeBookmark}
+
+	if obj, ok := any(PT(&evt.Object)).(interface {
+		SetResourceVersion(string)
+		SetAnnotations(map[string]string)
+	}); ok {
+		obj.SetResourceVersion(sw.resourceVersion)
+		obj.SetAnnotations(map[string]string{types.InitialEventsEndAnnotation: "true"})
+	}
+
+	return evt
+}
+
+// relistAndWatch recovers from the watch's resourceVersion expiring, as
+// reported by err, by listing the collection again and restarting the
+// watch from the list's resourceVersion. The events for the listed items
+// are queued for Next to return first.
+func (sw *Watcher[T, PT]) relistAndWatch(err error) error {
+	if sw.relist == nil {
+		return err
+	}
+	if sw.closer != nil {
+		sw.closer.Close()
+	}
+
+	// The list takes the place of any initial events still to come
+	initialEvents := sw.initialEvents
+	sw.endInitialEvents()
+
+	items, resourceVersion, err := sw.relist()
+	if err != nil {
+		return err
+	}
+
+	if sw.onRelist != nil {
+		sw.pending = append(sw.pending, sw.onRelist(items)...)
+	} else {
+		for _, item := range items {
+			sw.pending = append(sw.pending, types.Event[T, PT]{
+				Type:   types.EventTypeAdded,
+				Object: item,
+			})
+		}
+	}
+
+	sw.resourceVersion = resourceVersion
+	if initialEvents {
+		sw.pending = append(sw.pending, sw.initialEventsEnd())
+	}
+
+	return sw.doWatch()
+}
+
+// decodeError decodes the Status from an ERROR event as a StatusError
+func (sw *Watcher[T, PT]) decodeError(raw codec.RawEvent) error {
+	var status metav1.Status
+	if err := sw.codec.Unmarshal(raw.Object, &status); err != nil {
+		return fmt.Errorf("unable to decode watch error: %w", err)
+	}
+
+	return &client.StatusError{ErrStatus: status}
+}
+
 // receive reads result from the decoder in a loop and sends down the result channel.
 func (sw *Watcher[T, PT]) Next() (types.Event[T, PT], error) {
 	for {
 		var evt types.Event[T, PT]
-		err := sw.decoder.Decode(&evt)
+		if sw.closed.Load() {
+			return evt, io.EOF
+		}
+		if len(sw.pending) > 0 {
+			evt, sw.pending = sw.pending[0], sw.pending[1:]
+			return evt, nil
+		}
+
+		var raw codec.RawEvent
+		err := sw.decoder.Decode(&raw)
+
+		switch {
+		// The apiserver has sent an error, which ends the watch. If it is
+		// because our resourceVersion is too old, we can recover by
+		// relisting, otherwise it is returned to the caller.
+		case err == nil && types.EventType(raw.Type) == types.EventTypeError:
+			err = sw.decodeError(raw)
+			if !client.IsGone(err) {
+				return evt, err
+			}
+			if err = sw.relistAndWatch(err); err != nil {
+				return evt, err
+			}
 
-		switch err {
 		// Success case. Make a note of the latest resource version and then
 		// return the event to the caller.
-		case nil:
+		case err == nil:
+			evt.Type = types.EventType(raw.Type)
+			if err := sw.codec.Unmarshal(raw.Object, &evt.Object); err != nil {
+				return evt, err
+			}
 			sw.resourceVersion = PT(&evt.Object).GetResourceVersion()
+
+			// Bookmarks only exist to advance our resourceVersion, so are
+			// not passed on, except for the end of the initial events.
+			if evt.Type == types.EventTypeBookmark {
+				if sw.initialEvents && evt.IsInitialEventsEnd() {
+					sw.endInitialEvents()
+					return evt, nil
+				}
+				continue
+			}
+
 			return evt, nil
 
 		// Graceful closure of the underlying io.Reader, normally caused by
 		// a timeout. Attempt to reconnect.
-		case io.EOF:
-			// If the decoder is nil, we can't restart and should just return.
-			// This will either happen if you call Next() before intially
-			// starting the watcher, or because it has been explictly Closed()
-			if sw.decoder == nil {
+		case err == io.EOF:
+			// If the watcher has been explicitly Closed(), we shouldn't
+			// restart and should just return.
+			if sw.closed.Load() {
 				return evt, err
 			}
 
-			if err = sw.doWatch(); err != nil {
+			// Likewise, if the context has been cancelled there is no point
+			// trying to reconnect.
+			if ctxErr := sw.ctx.Err(); ctxErr != nil {
+				return evt, ctxErr
+			}
+
+			// Reconnecting part way through the initial events would replay
+			// them all, without DELETED events for objects removed in the
+			// meantime, so we relist instead, which onRelist reconciles.
+			if sw.initialEvents && sw.relist != nil {
+				err = sw.relistAndWatch(err)
+			} else {
+				err = sw.watch()
+			}
+			if err != nil {
 				return evt, err
 			}
 
@@ -0,0 +1,26 @@
+package cert
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+)
+
+// CertificateProvider is a generic interface for a service that provides
+// the x509 client certificate for the client to authenticate with
+type CertificateProvider interface {
+
+	// Retrieves the current certificate and its private key. This is
+	// called whenever a new connection is made to the apiserver, so
+	// providers may return a different certificate each time, for example
+	// after it has been rotated.
+	Certificate() (*tls.Certificate, error)
+}
+
+// CAProvider is a generic interface for a service that provides the
+// pool of certificate authorities used to verify the apiserver
+type CAProvider interface {
+
+	// Retrieves the current pool. This is called whenever a new
+	// connection is made to the apiserver.
+	RootCAs() *x509.CertPool
+}
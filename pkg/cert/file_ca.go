@@ -0,0 +1,82 @@
+package cert
+
+import (
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/EmilyShepherd/k8s-client-go/pkg/filewatch"
+)
+
+// FileCA is a CAProvider for a CA bundle which is backed by a file, such
+// as the service account's ca.crt. The file is watched and re-read when
+// it changes.
+type FileCA struct {
+	filename string
+	watcher  *filewatch.Watcher
+	mutex    sync.RWMutex
+	pool     *x509.CertPool
+}
+
+// NewFileCA reads the PEM encoded CA bundle from the given file, and then
+// watches it for changes, re-reading it every filewatch.DefaultInterval
+// regardless.
+func NewFileCA(filename string) (*FileCA, error) {
+	return NewFileCAWithInterval(filename, filewatch.DefaultInterval)
+}
+
+// NewFileCAWithInterval is as NewFileCA, but with a custom interval for
+// the periodic re-read. An interval of zero disables it.
+func NewFileCAWithInterval(filename string, interval time.Duration) (*FileCA, error) {
+	fileCA := &FileCA{
+		filename: filename,
+	}
+
+	watcher, err := filewatch.New(filename, interval, fileCA.reload)
+	if err != nil {
+		return nil, err
+	}
+	fileCA.watcher = watcher
+
+	return fileCA, nil
+}
+
+// reload re-reads the bundle. If this fails, or the file contains no
+// certificates, the previous pool is kept.
+func (c *FileCA) reload() error {
+	value, err := os.ReadFile(c.filename)
+	if err != nil {
+		return err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(value) {
+		return fmt.Errorf("no certificates found in %s", c.filename)
+	}
+
+	c.mutex.Lock()
+	c.pool = pool
+	c.mutex.Unlock()
+
+	return nil
+}
+
+func (c *FileCA) RootCAs() *x509.CertPool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.pool
+}
+
+// LastError returns the error from the last attempt to reload the
+// bundle, or from watching the file, if either failed.
+func (c *FileCA) LastError() error {
+	return c.watcher.LastError()
+}
+
+// Close stops watching the file.
+func (c *FileCA) Close() error {
+	return c.watcher.Close()
+}
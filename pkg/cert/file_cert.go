@@ -0,0 +1,87 @@
+package cert
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileCertificate is a CertificateProvider for a certificate and key
+// which are backed by files on disk.
+//
+// The files are checked each time a certificate is requested, and are
+// re-read if either has changed. This means that certificates which are
+// rotated on disk (as the kubelet does with its client certificate) are
+// picked up by new connections without restarting the process. The
+// files are checked with stat(), which follows symlinks, so this also
+// works when rotation is done by swapping a symlink rather than by
+// writing to the file itself.
+type FileCertificate struct {
+	certFile string
+	keyFile  string
+
+	mutex    sync.Mutex
+	cert     *tls.Certificate
+	certStat fileStat
+	keyStat  fileStat
+}
+
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFile(filename string) (fileStat, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return fileStat{}, err
+	}
+
+	return fileStat{modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// NewFileCertificate loads the certificate and key from the given files.
+// They may be the same file, if it contains both PEM blocks.
+func NewFileCertificate(certFile, keyFile string) (*FileCertificate, error) {
+	fileCert := FileCertificate{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+
+	if _, err := fileCert.Certificate(); err != nil {
+		return nil, err
+	}
+
+	return &fileCert, nil
+}
+
+// Certificate returns the certificate from disk, reloading it if the
+// files have changed since it was last read. If the files can't be
+// re-read, or don't contain a valid pair (for example because we've
+// caught them half way through being rotated), the last good certificate
+// is returned.
+func (c *FileCertificate) Certificate() (*tls.Certificate, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	certStat, certErr := statFile(c.certFile)
+	keyStat, keyErr := statFile(c.keyFile)
+	if certErr == nil && keyErr == nil && certStat == c.certStat && keyStat == c.keyStat {
+		return c.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		if c.cert != nil {
+			return c.cert, nil
+		}
+		return nil, err
+	}
+
+	c.cert = &cert
+	c.certStat = certStat
+	c.keyStat = keyStat
+
+	return c.cert, nil
+}
@@ -0,0 +1,25 @@
+package cert
+
+import (
+	"crypto/tls"
+)
+
+// StaticCertificate is a CertificateProvider wrapper for a fixed
+// certificate and key pair
+type StaticCertificate struct {
+	cert tls.Certificate
+}
+
+// NewStaticCertificate parses the given PEM encoded certificate and key
+func NewStaticCertificate(certPEM, keyPEM []byte) (*StaticCertificate, error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StaticCertificate{cert: cert}, nil
+}
+
+func (c *StaticCertificate) Certificate() (*tls.Certificate, error) {
+	return &c.cert, nil
+}
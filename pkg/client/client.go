@@ -1,25 +1,55 @@
 package client
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"time"
 
+	"golang.org/x/time/rate"
+
+	"github.com/EmilyShepherd/k8s-client-go/pkg/cert"
+	"github.com/EmilyShepherd/k8s-client-go/pkg/codec"
 	"github.com/EmilyShepherd/k8s-client-go/pkg/token"
+	"github.com/EmilyShepherd/k8s-client-go/pkg/trace"
 )
 
 type Client struct {
 	HttpClient   *http.Client
 	apiServerURL string
 
-	token token.TokenProvider
+	token      token.TokenProvider
+	tlsConfig  *tls.Config
+	caProvider cert.CAProvider
+	insecure   bool
+
+	readLimiter       *rate.Limiter
+	writeLimiter      *rate.Limiter
+	rateLimitObserver RateLimitObserver
+
+	retryPolicy RetryPolicy
+
+	middleware []Middleware
+	handler    Handler
+
+	metrics *clientMetrics
+	tracer  trace.Tracer
+
+	impersonate    *ImpersonationConfig
+	warningHandler WarningHandler
 }
 
+// Option configures optional behaviour of a Client when passed to
+// NewClient.
+type Option func(*Client)
+
 const (
 	serviceAccountToken  = "/var/run/secrets/kubernetes.io/serviceaccount/token"
 	serviceAccountCACert = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
@@ -35,59 +65,286 @@ func NewInCluster() (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	ca, err := ioutil.ReadFile(serviceAccountCACert)
+	ca, err := cert.NewFileCA(serviceAccountCACert)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewClient("https://"+net.JoinHostPort(host, port), tp, ca)
+	return NewClient("https://"+net.JoinHostPort(host, port), tp, nil, WithRootCAProvider(ca))
+}
+
+// NewDefault creates a Client using the in-cluster configuration if it
+// is available, falling back to the user's kubeconfig otherwise.
+func NewDefault() (*Client, error) {
+	kc, err := NewInCluster()
+	if err == nil {
+		return kc, nil
+	}
+
+	kc, kerr := NewFromKubeconfig()
+	if kerr != nil {
+		return nil, fmt.Errorf("unable to load in-cluster configuration (%v) or kubeconfig (%w)", err, kerr)
+	}
+
+	return kc, nil
 }
 
-func NewClient(host string, tp token.TokenProvider, ca []byte) (*Client, error) {
+// NewClient creates a Client for the apiserver at the given host. The
+// token provider may be nil if the client authenticates by some other
+// means, such as a client certificate.
+func NewClient(host string, tp token.TokenProvider, ca []byte, opts ...Option) (*Client, error) {
 	certPool := x509.NewCertPool()
 	certPool.AppendCertsFromPEM(ca)
-	transport := &http.Transport{TLSClientConfig: &tls.Config{
+	tlsConfig := &tls.Config{
 		MinVersion: tls.VersionTLS12,
 		RootCAs:    certPool,
-	}}
+	}
+	transport := &http.Transport{TLSClientConfig: tlsConfig}
 
-	return &Client{
+	kc := &Client{
 		apiServerURL: host,
 		token:        tp,
+		tlsConfig:    tlsConfig,
 		HttpClient: &http.Client{
 			Transport: transport,
 			Timeout:   time.Nanosecond * 0,
 		},
-	}, nil
+	}
+
+	for _, opt := range opts {
+		opt(kc)
+	}
+
+	kc.handler = kc.buildChain()
+
+	// Go's TLS stack only supports a fixed pool of root CAs, so to allow
+	// them to change we have to turn off its verification and do it
+	// ourselves.
+	if kc.caProvider != nil && !kc.insecure {
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyConnection = kc.verifyConnection
+	}
+
+	return kc, nil
+}
+
+// WithInsecureSkipTLSVerify disables verification of the apiserver's
+// serving certificate. This should only be used for testing.
+func WithInsecureSkipTLSVerify() Option {
+	return func(kc *Client) {
+		kc.tlsConfig.InsecureSkipVerify = true
+		kc.insecure = true
+	}
+}
+
+// WithTLSServerName sets the name used to verify the apiserver's serving
+// certificate, and sent as SNI, instead of the hostname from its URL.
+func WithTLSServerName(name string) Option {
+	return func(kc *Client) {
+		kc.tlsConfig.ServerName = name
+	}
+}
+
+// WithRootCAProvider sets the provider of the CAs used to verify the
+// apiserver's serving certificate, in place of the bundle given to
+// NewClient.
+func WithRootCAProvider(cp cert.CAProvider) Option {
+	return func(kc *Client) {
+		kc.caProvider = cp
+	}
+}
+
+// verifyConnection checks the apiserver's certificate chain against the
+// current pool from the CAProvider.
+func (kc *Client) verifyConnection(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return fmt.Errorf("apiserver presented no certificates")
+	}
+
+	name := kc.tlsConfig.ServerName
+	if name == "" {
+		u, err := url.Parse(kc.apiServerURL)
+		if err != nil {
+			return err
+		}
+		name = u.Hostname()
+	}
+
+	opts := x509.VerifyOptions{
+		DNSName:       name,
+		Roots:         kc.caProvider.RootCAs(),
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, intermediate := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(intermediate)
+	}
+
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
 }
 
+// WithCertificateProvider sets the provider of the x509 certificate the
+// client presents to the apiserver to authenticate itself.
+func WithCertificateProvider(cp cert.CertificateProvider) Option {
+	return func(kc *Client) {
+		kc.tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return cp.Certificate()
+		}
+	}
+}
+
+// WithTokenProvider returns a copy of the client which authenticates
+// with the given token provider instead. The copy shares the original's
+// transport and connection pool.
+//
+// Note that if the original client authenticates with a client
+// certificate, it will still be presented by the copy, and the apiserver
+// may prefer it over the token.
+func (kc *Client) WithTokenProvider(tp token.TokenProvider) *Client {
+	newKc := *kc
+	newKc.token = tp
+	newKc.handler = newKc.buildChain()
+
+	return &newKc
+}
+
+// DoRaw sends the given request to the apiserver, through the client's
+// middleware, adding the client's credentials to it. The request's
+// context controls its lifetime.
 func (kc *Client) DoRaw(req *http.Request) (*http.Response, error) {
-	if token := kc.token.Token(); len(token) > 0 {
-		req.Header.Set("Authorization", "Bearer "+token)
+	return kc.handler(ResourceRequest{}, req)
+}
+
+// send adds the client's credentials, and any impersonation headers, to
+// the request and sends it.
+func (kc *Client) send(req *http.Request) (*http.Response, error) {
+	kc.setImpersonationHeaders(req)
+
+	if kc.token != nil {
+		token, _, err := kc.token.Token(req.Context())
+		if err != nil {
+			return nil, fmt.Errorf("unable to get token: %w", err)
+		}
+		if len(token) > 0 {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
 	}
 	return kc.HttpClient.Do(req)
 }
 
-func (kc *Client) Do(r ResourceRequest) (*http.Response, error) {
-	req, err := http.NewRequest(r.Verb, kc.apiServerURL+r.URL(), r.Body)
+// doAuthenticated sends the request, and if the apiserver rejects the
+// token, invalidates it and tries once more with a fresh one.
+func (kc *Client) doAuthenticated(_ ResourceRequest, req *http.Request) (*http.Response, error) {
+	resp, err := kc.send(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	invalidator, ok := kc.token.(token.Invalidator)
+	if !ok || (req.Body != nil && req.GetBody == nil) {
+		return resp, nil
+	}
+
+	resp.Body.Close()
+	invalidator.Invalidate()
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	return kc.send(retry)
+}
+
+// Do builds and sends a request for the given ResourceRequest. If ctx is
+// cancelled, or its deadline expires, the request is aborted; for
+// streaming responses this also closes the response body.
+//
+// If the client is rate limited, each attempt first waits for the
+// limiter, which also respects ctx. Requests which fail for transient
+// reasons are retried according to the RetryPolicy, if one is set; see
+// RetryPolicy for which requests are retried.
+//
+// If the client has a tracer, the whole call, including any retries, is
+// recorded as a single span.
+func (kc *Client) Do(ctx context.Context, r ResourceRequest) (*http.Response, error) {
+	ctx, span := kc.startSpan(ctx, r)
+	resp, err := kc.doWithRetries(ctx, r)
+	endSpan(span, resp, err)
+
+	return resp, err
+}
+
+func (kc *Client) doWithRetries(ctx context.Context, r ResourceRequest) (*http.Response, error) {
+	policy := kc.retryPolicyFor(ctx, r)
+
+	for attempt := 0; ; attempt++ {
+		resp, err := kc.doOnce(ctx, r)
+
+		delay, retry := policy.backoff(r, attempt, resp, err)
+		if !retry {
+			return resp, err
+		}
+
+		kc.metrics.observeRetry(r)
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// doOnce makes a single attempt at the request. For non-2xx responses,
+// the response is returned, with its body already consumed and closed,
+// along with a StatusError.
+func (kc *Client) doOnce(ctx context.Context, r ResourceRequest) (*http.Response, error) {
+	if err := kc.waitForRateLimit(ctx, r); err != nil {
+		return nil, err
+	}
+
+	var body io.Reader
+	if r.Body != nil {
+		body = bytes.NewReader(r.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.Verb, kc.apiServerURL+r.URL(), body)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Accept", "application/json,application/vnd.kubernetes.protobuf")
+	if r.Accept != "" {
+		req.Header.Set("Accept", r.Accept)
+	} else {
+		req.Header.Set("Accept", codec.JSON.MediaType())
+	}
 
 	if r.ContentType != "" {
 		req.Header.Set("Content-Type", string(r.ContentType))
 	}
 
-	resp, err := kc.DoRaw(req)
+	injectTraceParent(ctx, req)
+
+	start := time.Now()
+	resp, err := kc.handler(r, req)
+	kc.metrics.observeRequest(r, resp, time.Since(start))
 	if err != nil {
 		return nil, err
 	}
+
+	warningErr := kc.handleWarnings(ctx, resp)
+
 	if resp.StatusCode < 200 || resp.StatusCode > 226 {
 		defer resp.Body.Close()
-		errmsg, _ := ioutil.ReadAll(resp.Body)
-		return resp, fmt.Errorf("invalid response code %d for request url : %s", resp.StatusCode, errmsg)
+		return resp, newStatusError(resp)
+	}
+	if warningErr != nil {
+		resp.Body.Close()
+		return resp, warningErr
+	}
+
+	if r.IsWatch() {
+		resp.Body = kc.metrics.trackWatch(r, resp.Body)
 	}
 
 	return resp, nil
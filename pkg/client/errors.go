@@ -0,0 +1,237 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/EmilyShepherd/k8s-client-go/pkg/codec"
+)
+
+// StatusError is returned for any non-2xx response from the apiserver.
+// It holds the [metav1.Status] which the apiserver sent as the body of
+// the response, or one built from the response code if the body was not
+// a Status.
+type StatusError struct {
+	ErrStatus metav1.Status
+}
+
+func (e *StatusError) Error() string {
+	return e.ErrStatus.Message
+}
+
+// Status returns the Status which caused this error
+func (e *StatusError) Status() metav1.Status {
+	return e.ErrStatus
+}
+
+// Code returns the HTTP response code
+func (e *StatusError) Code() int32 {
+	return e.ErrStatus.Code
+}
+
+// Reason returns the machine readable reason for the error, if the
+// apiserver gave one
+func (e *StatusError) Reason() metav1.StatusReason {
+	return e.ErrStatus.Reason
+}
+
+// Details returns the extended details of the error, such as the name
+// and kind of the object concerned, if the apiserver gave any
+func (e *StatusError) Details() *metav1.StatusDetails {
+	return e.ErrStatus.Details
+}
+
+// Causes returns the individual causes of the error, such as each
+// invalid field in a validation failure
+func (e *StatusError) Causes() []metav1.StatusCause {
+	if e.ErrStatus.Details == nil {
+		return nil
+	}
+	return e.ErrStatus.Details.Causes
+}
+
+// newStatusError reads the response's body, which for an error is
+// normally a Status, and builds a StatusError from it. The Status may be
+// encoded as protobuf or CBOR if the request asked for it.
+func newStatusError(resp *http.Response) *StatusError {
+	body, _ := io.ReadAll(resp.Body)
+
+	var status metav1.Status
+	c := codec.ForContentType(resp.Header.Get("Content-Type"), codec.Protobuf, codec.CBOR)
+	if err := c.Unmarshal(body, &status); err != nil || status.Kind != "Status" {
+		status = metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  reasonForCode(resp.StatusCode),
+			Message: strings.TrimSpace(string(body)),
+		}
+	}
+
+	if status.Code == 0 {
+		status.Code = int32(resp.StatusCode)
+	}
+	if status.Message == "" {
+		status.Message = fmt.Sprintf("the server responded with the status code %d but did not return more information", resp.StatusCode)
+	}
+
+	return &StatusError{ErrStatus: status}
+}
+
+// NewNotFound returns a StatusError like the apiserver would if the
+// given object did not exist.
+func NewNotFound(resource, name string) *StatusError {
+	return &StatusError{ErrStatus: metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusNotFound,
+		Reason:  metav1.StatusReasonNotFound,
+		Message: fmt.Sprintf("%s %q not found", resource, name),
+		Details: &metav1.StatusDetails{
+			Name: name,
+			Kind: resource,
+		},
+	}}
+}
+
+func reasonForCode(code int) metav1.StatusReason {
+	switch code {
+	case http.StatusBadRequest:
+		return metav1.StatusReasonBadRequest
+	case http.StatusUnauthorized:
+		return metav1.StatusReasonUnauthorized
+	case http.StatusForbidden:
+		return metav1.StatusReasonForbidden
+	case http.StatusNotFound:
+		return metav1.StatusReasonNotFound
+	case http.StatusMethodNotAllowed:
+		return metav1.StatusReasonMethodNotAllowed
+	case http.StatusNotAcceptable:
+		return metav1.StatusReasonNotAcceptable
+	case http.StatusConflict:
+		return metav1.StatusReasonConflict
+	case http.StatusGone:
+		return metav1.StatusReasonGone
+	case http.StatusRequestEntityTooLarge:
+		return metav1.StatusReasonRequestEntityTooLarge
+	case http.StatusUnsupportedMediaType:
+		return metav1.StatusReasonUnsupportedMediaType
+	case http.StatusUnprocessableEntity:
+		return metav1.StatusReasonInvalid
+	case http.StatusTooManyRequests:
+		return metav1.StatusReasonTooManyRequests
+	case http.StatusInternalServerError:
+		return metav1.StatusReasonInternalError
+	case http.StatusServiceUnavailable:
+		return metav1.StatusReasonServiceUnavailable
+	case http.StatusGatewayTimeout:
+		return metav1.StatusReasonTimeout
+	}
+
+	return metav1.StatusReasonUnknown
+}
+
+// hasReason checks whether err is a StatusError with the given reason.
+// If the apiserver didn't give a reason, the response code is checked
+// instead.
+func hasReason(err error, reason metav1.StatusReason, code int32) bool {
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+
+	if statusErr.Reason() != metav1.StatusReasonUnknown {
+		return statusErr.Reason() == reason
+	}
+
+	return statusErr.Code() == code
+}
+
+// IsNotFound returns true if err is a StatusError for an object or
+// resource which does not exist
+func IsNotFound(err error) bool {
+	return hasReason(err, metav1.StatusReasonNotFound, http.StatusNotFound)
+}
+
+// IsAlreadyExists returns true if err is a StatusError for an object
+// which could not be created because it already exists
+func IsAlreadyExists(err error) bool {
+	return hasReason(err, metav1.StatusReasonAlreadyExists, http.StatusConflict)
+}
+
+// IsConflict returns true if err is a StatusError for a write which
+// conflicted with another, for example due to a stale resourceVersion
+func IsConflict(err error) bool {
+	return hasReason(err, metav1.StatusReasonConflict, http.StatusConflict)
+}
+
+// IsGone returns true if err is a StatusError for a resource which is no
+// longer available. This includes resourceVersions and continue tokens
+// which have expired, which the apiserver also reports with a 410.
+func IsGone(err error) bool {
+	return hasReason(err, metav1.StatusReasonGone, http.StatusGone) || IsResourceExpired(err)
+}
+
+// IsResourceExpired returns true if err is a StatusError for a request
+// with a resourceVersion, or continue token, which is too old
+func IsResourceExpired(err error) bool {
+	return hasReason(err, metav1.StatusReasonExpired, http.StatusGone)
+}
+
+// IsBadRequest returns true if err is a StatusError for a request which
+// the apiserver could not understand
+func IsBadRequest(err error) bool {
+	return hasReason(err, metav1.StatusReasonBadRequest, http.StatusBadRequest)
+}
+
+// IsNotAcceptable returns true if err is a StatusError for a request
+// whose Accept header asked only for media types the apiserver can't
+// send
+func IsNotAcceptable(err error) bool {
+	return hasReason(err, metav1.StatusReasonNotAcceptable, http.StatusNotAcceptable)
+}
+
+// IsUnsupportedMediaType returns true if err is a StatusError for a
+// request whose body was in a media type the apiserver can't decode
+func IsUnsupportedMediaType(err error) bool {
+	return hasReason(err, metav1.StatusReasonUnsupportedMediaType, http.StatusUnsupportedMediaType)
+}
+
+// InconsistentContinue returns the continue token which the apiserver
+// may send when a List's continue token has expired. It can be used to
+// carry on with the List, from a newer snapshot of the collection, so the
+// items already returned may be out of date.
+func InconsistentContinue(err error) (string, bool) {
+	var statusErr *StatusError
+	if !IsResourceExpired(err) || !errors.As(err, &statusErr) || statusErr.ErrStatus.Continue == "" {
+		return "", false
+	}
+
+	return statusErr.ErrStatus.Continue, true
+}
+
+// IsInvalid returns true if err is a StatusError for an object which
+// failed validation
+func IsInvalid(err error) bool {
+	return hasReason(err, metav1.StatusReasonInvalid, http.StatusUnprocessableEntity)
+}
+
+// IsTooManyRequests returns true if err is a StatusError for a request
+// which was rejected by rate limiting or API Priority and Fairness
+func IsTooManyRequests(err error) bool {
+	return hasReason(err, metav1.StatusReasonTooManyRequests, http.StatusTooManyRequests)
+}
+
+// IsUnauthorized returns true if err is a StatusError for a request
+// whose credentials were missing or invalid
+func IsUnauthorized(err error) bool {
+	return hasReason(err, metav1.StatusReasonUnauthorized, http.StatusUnauthorized)
+}
+
+// IsForbidden returns true if err is a StatusError for a request which
+// the client is not permitted to make
+func IsForbidden(err error) bool {
+	return hasReason(err, metav1.StatusReasonForbidden, http.StatusForbidden)
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ImpersonationConfig describes the user a client acts on behalf of. The
+// apiserver authorizes requests as this user, provided the client's own
+// identity is allowed to impersonate it.
+//
+// See https://kubernetes.io/docs/reference/access-authn-authz/authentication/#user-impersonation
+type ImpersonationConfig struct {
+	UserName string
+	UID      string
+	Groups   []string
+	Extra    map[string][]string
+}
+
+// Impersonate returns a copy of the client which acts on behalf of the
+// given user. The copy shares the original's transport and connection
+// pool.
+func (kc *Client) Impersonate(config ImpersonationConfig) *Client {
+	newKc := *kc
+	newKc.impersonate = &config
+	newKc.handler = newKc.buildChain()
+
+	return &newKc
+}
+
+type impersonationKey struct{}
+
+// ContextWithImpersonation returns a context which makes any request made
+// with it act on behalf of the given user, overriding the client's.
+func ContextWithImpersonation(ctx context.Context, config ImpersonationConfig) context.Context {
+	return context.WithValue(ctx, impersonationKey{}, config)
+}
+
+// setImpersonationHeaders adds the Impersonate-* headers for the request
+// context's impersonation, or else the client's.
+func (kc *Client) setImpersonationHeaders(req *http.Request) {
+	config, ok := req.Context().Value(impersonationKey{}).(ImpersonationConfig)
+	if !ok {
+		if kc.impersonate == nil {
+			return
+		}
+		config = *kc.impersonate
+	}
+
+	// The same request may be sent more than once, so clear anything left
+	// over from a previous attempt.
+	for name := range req.Header {
+		if strings.HasPrefix(name, "Impersonate-") {
+			req.Header.Del(name)
+		}
+	}
+
+	if config.UserName != "" {
+		req.Header.Set("Impersonate-User", config.UserName)
+	}
+	if config.UID != "" {
+		req.Header.Set("Impersonate-Uid", config.UID)
+	}
+	for _, group := range config.Groups {
+		req.Header.Add("Impersonate-Group", group)
+	}
+	for key, values := range config.Extra {
+		// Extra keys may contain characters which aren't valid in header
+		// names, so are percent-encoded.
+		name := "Impersonate-Extra-" + url.PathEscape(key)
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/EmilyShepherd/k8s-client-go/pkg/cert"
+	"github.com/EmilyShepherd/k8s-client-go/pkg/token"
+)
+
+// Kubeconfig is the subset of the kubeconfig file format which is
+// required to connect to a cluster.
+//
+// See https://kubernetes.io/docs/concepts/configuration/organize-cluster-access-kubeconfig/
+type Kubeconfig struct {
+	CurrentContext string         `json:"current-context"`
+	Clusters       []NamedCluster `json:"clusters"`
+	Users          []NamedUser    `json:"users"`
+	Contexts       []NamedContext `json:"contexts"`
+}
+
+type NamedCluster struct {
+	Name    string  `json:"name"`
+	Cluster Cluster `json:"cluster"`
+}
+
+type Cluster struct {
+	Server                   string `json:"server"`
+	TLSServerName            string `json:"tls-server-name,omitempty"`
+	InsecureSkipTLSVerify    bool   `json:"insecure-skip-tls-verify,omitempty"`
+	CertificateAuthority     string `json:"certificate-authority,omitempty"`
+	CertificateAuthorityData []byte `json:"certificate-authority-data,omitempty"`
+}
+
+type NamedUser struct {
+	Name string   `json:"name"`
+	User AuthInfo `json:"user"`
+}
+
+type AuthInfo struct {
+	ClientCertificate     string `json:"client-certificate,omitempty"`
+	ClientCertificateData []byte `json:"client-certificate-data,omitempty"`
+	ClientKey             string `json:"client-key,omitempty"`
+	ClientKeyData         []byte `json:"client-key-data,omitempty"`
+	Token                 string `json:"token,omitempty"`
+	TokenFile             string `json:"tokenFile,omitempty"`
+
+	Exec *token.ExecConfig `json:"exec,omitempty"`
+}
+
+type NamedContext struct {
+	Name    string  `json:"name"`
+	Context Context `json:"context"`
+}
+
+type Context struct {
+	Cluster   string `json:"cluster"`
+	User      string `json:"user"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// KubeconfigPaths returns the kubeconfig files to load, in order of
+// precedence. This is the list in $KUBECONFIG if it is set, otherwise
+// ~/.kube/config.
+func KubeconfigPaths() []string {
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		var paths []string
+		for _, path := range filepath.SplitList(env) {
+			if path != "" {
+				paths = append(paths, path)
+			}
+		}
+		return paths
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+
+	return []string{filepath.Join(home, ".kube", "config")}
+}
+
+// LoadKubeconfig reads and merges the given kubeconfig files. As with
+// kubectl, the first file to set the current-context, or to define a
+// cluster, user or context of a given name, wins. Files which do not
+// exist are skipped. Relative file references within each file are
+// resolved against that file's directory.
+func LoadKubeconfig(paths ...string) (*Kubeconfig, error) {
+	merged := &Kubeconfig{}
+	clusters := map[string]bool{}
+	users := map[string]bool{}
+	contexts := map[string]bool{}
+	found := false
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		found = true
+
+		var config Kubeconfig
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("unable to parse kubeconfig %s: %w", path, err)
+		}
+		config.resolvePaths(filepath.Dir(path))
+
+		if merged.CurrentContext == "" {
+			merged.CurrentContext = config.CurrentContext
+		}
+		for _, cluster := range config.Clusters {
+			if !clusters[cluster.Name] {
+				clusters[cluster.Name] = true
+				merged.Clusters = append(merged.Clusters, cluster)
+			}
+		}
+		for _, user := range config.Users {
+			if !users[user.Name] {
+				users[user.Name] = true
+				merged.Users = append(merged.Users, user)
+			}
+		}
+		for _, context := range config.Contexts {
+			if !contexts[context.Name] {
+				contexts[context.Name] = true
+				merged.Contexts = append(merged.Contexts, context)
+			}
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no kubeconfig found in %v", paths)
+	}
+
+	return merged, nil
+}
+
+func (c *Kubeconfig) resolvePaths(dir string) {
+	resolve := func(path *string) {
+		if *path != "" && !filepath.IsAbs(*path) {
+			*path = filepath.Join(dir, *path)
+		}
+	}
+
+	for i := range c.Clusters {
+		resolve(&c.Clusters[i].Cluster.CertificateAuthority)
+	}
+	for i := range c.Users {
+		resolve(&c.Users[i].User.ClientCertificate)
+		resolve(&c.Users[i].User.ClientKey)
+		resolve(&c.Users[i].User.TokenFile)
+
+		// Exec commands are only relative to the kubeconfig if they are a
+		// path, otherwise they are looked up in $PATH.
+		if exec := c.Users[i].User.Exec; exec != nil && strings.ContainsRune(exec.Command, filepath.Separator) {
+			resolve(&exec.Command)
+		}
+	}
+}
+
+// Context returns the named context, or the current context if name is
+// empty.
+func (c *Kubeconfig) Context(name string) (*Context, error) {
+	if name == "" {
+		name = c.CurrentContext
+	}
+	if name == "" {
+		return nil, fmt.Errorf("kubeconfig has no current-context")
+	}
+
+	for _, context := range c.Contexts {
+		if context.Name == name {
+			return &context.Context, nil
+		}
+	}
+
+	return nil, fmt.Errorf("context %q not found in kubeconfig", name)
+}
+
+func (c *Kubeconfig) cluster(name string) (*Cluster, error) {
+	for _, cluster := range c.Clusters {
+		if cluster.Name == name {
+			return &cluster.Cluster, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cluster %q not found in kubeconfig", name)
+}
+
+func (c *Kubeconfig) user(name string) (*AuthInfo, error) {
+	for _, user := range c.Users {
+		if user.Name == name {
+			return &user.User, nil
+		}
+	}
+
+	return nil, fmt.Errorf("user %q not found in kubeconfig", name)
+}
+
+// Client creates a Client for the named context, or the current context
+// if name is empty.
+func (c *Kubeconfig) Client(name string) (*Client, error) {
+	context, err := c.Context(name)
+	if err != nil {
+		return nil, err
+	}
+	cluster, err := c.cluster(context.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	// A context is allowed to omit the user, in which case the client is
+	// anonymous.
+	user := &AuthInfo{}
+	if context.User != "" {
+		if user, err = c.user(context.User); err != nil {
+			return nil, err
+		}
+	}
+
+	ca := cluster.CertificateAuthorityData
+	if len(ca) == 0 && cluster.CertificateAuthority != "" {
+		if ca, err = os.ReadFile(cluster.CertificateAuthority); err != nil {
+			return nil, err
+		}
+	}
+
+	var opts []Option
+	if cluster.InsecureSkipTLSVerify {
+		opts = append(opts, WithInsecureSkipTLSVerify())
+	}
+	if cluster.TLSServerName != "" {
+		opts = append(opts, WithTLSServerName(cluster.TLSServerName))
+	}
+
+	var cp cert.CertificateProvider
+	if len(user.ClientCertificateData) > 0 || len(user.ClientKeyData) > 0 {
+		cp, err = cert.NewStaticCertificate(user.ClientCertificateData, user.ClientKeyData)
+	} else if user.ClientCertificate != "" || user.ClientKey != "" {
+		cp, err = cert.NewFileCertificate(user.ClientCertificate, user.ClientKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cp != nil {
+		opts = append(opts, WithCertificateProvider(cp))
+	}
+
+	var tp token.TokenProvider
+	if user.Exec != nil {
+		exec := *user.Exec
+		exec.Cluster = &token.ExecCluster{
+			Server:                   cluster.Server,
+			TLSServerName:            cluster.TLSServerName,
+			InsecureSkipTLSVerify:    cluster.InsecureSkipTLSVerify,
+			CertificateAuthorityData: ca,
+		}
+
+		execToken, err := token.NewExecToken(exec)
+		if err != nil {
+			return nil, err
+		}
+
+		// Plugins may return a client certificate instead of, or as well
+		// as, a token, but one set in the kubeconfig takes precedence.
+		tp = execToken
+		if cp == nil {
+			opts = append(opts, WithCertificateProvider(execToken))
+		}
+	} else if user.Token != "" {
+		tp, err = token.NewStaticToken(user.Token)
+	} else if user.TokenFile != "" {
+		tp, err = token.NewFileToken(user.TokenFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(strings.TrimSuffix(cluster.Server, "/"), tp, ca, opts...)
+}
+
+// NewFromKubeconfig creates a Client for the current context of the
+// kubeconfig files given by KubeconfigPaths.
+func NewFromKubeconfig() (*Client, error) {
+	config, err := LoadKubeconfig(KubeconfigPaths()...)
+	if err != nil {
+		return nil, err
+	}
+
+	return config.Client("")
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// writeClientCertificate writes a self-signed client certificate and its
+// key to dir, returning the certificate's DER encoding.
+func writeClientCertificate(t *testing.T, dir string) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "user"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(filepath.Join(dir, "client.crt"), certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "client.key"), keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return der
+}
+
+func TestKubeconfigClientCertificateWithExec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("exec plugin tests need a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	der := writeClientCertificate(t, dir)
+
+	// A plugin which only returns a token
+	plugin := `#!/bin/sh
+echo '{"apiVersion":"client.authentication.k8s.io/v1","kind":"ExecCredential","status":{"token":"abc"}}'
+`
+	if err := os.WriteFile(filepath.Join(dir, "plugin.sh"), []byte(plugin), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	config := `apiVersion: v1
+kind: Config
+current-context: test
+clusters:
+- name: test
+  cluster:
+    server: https://127.0.0.1:6443
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+users:
+- name: test
+  user:
+    client-certificate: client.crt
+    client-key: client.key
+    exec:
+      apiVersion: client.authentication.k8s.io/v1
+      command: ` + filepath.Join(dir, "plugin.sh") + `
+`
+	path := filepath.Join(dir, "config")
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	kubeconfig, err := LoadKubeconfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kc, err := kubeconfig.Client("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := kc.tlsConfig.GetClientCertificate(&tls.CertificateRequestInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cert.Certificate) != 1 || !bytes.Equal(cert.Certificate[0], der) {
+		t.Error("client does not present the certificate from the kubeconfig")
+	}
+
+	token, _, err := kc.token.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "abc" {
+		t.Errorf("client has token %q, want %q", token, "abc")
+	}
+}
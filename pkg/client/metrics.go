@@ -0,0 +1,142 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/EmilyShepherd/k8s-client-go/pkg/metrics"
+)
+
+// clientMetrics holds the metrics the client records. All of its methods
+// are safe to call on a nil *clientMetrics, in which case they do
+// nothing.
+type clientMetrics struct {
+	requests      metrics.Counter
+	latency       metrics.Histogram
+	rateLimitWait metrics.Histogram
+	retries       metrics.Counter
+	watches       metrics.Gauge
+}
+
+// WithMetrics records metrics about the client's requests in the given
+// registry:
+//
+//   - kubernetes_client_requests_total, by verb, group, version,
+//     resource, subresource and status code
+//   - kubernetes_client_request_duration_seconds, with the same labels
+//   - kubernetes_client_rate_limiter_wait_seconds, by verb, group,
+//     version, resource and subresource
+//   - kubernetes_client_retries_total, with the same labels
+//   - kubernetes_client_watches_in_flight, by group, version and resource
+//
+// Watches are recorded with the verb WATCH, and the code label is
+// "error" for requests which got no response.
+func WithMetrics(registry metrics.Registry) Option {
+	requestLabels := []string{"verb", "group", "version", "resource", "subresource"}
+	withCode := append(append([]string(nil), requestLabels...), "code")
+
+	return func(kc *Client) {
+		kc.metrics = &clientMetrics{
+			requests: registry.Counter(
+				"kubernetes_client_requests_total",
+				"Number of requests made to the apiserver.",
+				withCode...,
+			),
+			latency: registry.Histogram(
+				"kubernetes_client_request_duration_seconds",
+				"Time taken for the apiserver to respond to requests.",
+				metrics.DefBuckets,
+				withCode...,
+			),
+			rateLimitWait: registry.Histogram(
+				"kubernetes_client_rate_limiter_wait_seconds",
+				"Time requests spent waiting for the client side rate limiter.",
+				metrics.DefBuckets,
+				requestLabels...,
+			),
+			retries: registry.Counter(
+				"kubernetes_client_retries_total",
+				"Number of requests which were retried.",
+				requestLabels...,
+			),
+			watches: registry.Gauge(
+				"kubernetes_client_watches_in_flight",
+				"Number of watches currently open.",
+				"group", "version", "resource",
+			),
+		}
+	}
+}
+
+func requestLabels(r ResourceRequest) []string {
+	verb := r.Verb
+	if r.IsWatch() {
+		verb = "WATCH"
+	} else if verb == "" {
+		verb = http.MethodGet
+	}
+
+	return []string{verb, r.GVR.Group, r.GVR.Version, r.GVR.Resource, r.Subresource}
+}
+
+func (m *clientMetrics) observeRequest(r ResourceRequest, resp *http.Response, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	code := "error"
+	if resp != nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	labels := append(requestLabels(r), code)
+
+	m.requests.Add(1, labels...)
+	m.latency.Observe(duration.Seconds(), labels...)
+}
+
+func (m *clientMetrics) observeRateLimit(r ResourceRequest, wait time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.rateLimitWait.Observe(wait.Seconds(), requestLabels(r)...)
+}
+
+func (m *clientMetrics) observeRetry(r ResourceRequest) {
+	if m == nil {
+		return
+	}
+
+	m.retries.Add(1, requestLabels(r)...)
+}
+
+// trackWatch counts the watch as in flight until its body is closed.
+func (m *clientMetrics) trackWatch(r ResourceRequest, body io.ReadCloser) io.ReadCloser {
+	if m == nil {
+		return body
+	}
+
+	labels := []string{r.GVR.Group, r.GVR.Version, r.GVR.Resource}
+	m.watches.Add(1, labels...)
+
+	return &watchBody{
+		ReadCloser: body,
+		done: func() {
+			m.watches.Add(-1, labels...)
+		},
+	}
+}
+
+type watchBody struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (b *watchBody) Close() error {
+	b.once.Do(b.done)
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"net/http"
+)
+
+// Handler sends a single attempt at a request to the apiserver. The
+// ResourceRequest describes what the request is for; it is empty for
+// requests made with DoRaw.
+type Handler func(r ResourceRequest, req *http.Request) (*http.Response, error)
+
+// Middleware wraps a Handler to add cross-cutting behaviour, such as
+// logging, metrics, tracing, header injection or fault injection.
+//
+// A Middleware may inspect or modify the request before passing it on to
+// next, inspect or replace the response or error which next returns, or
+// not call next at all.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware adds middleware to the client. Middleware is called in
+// the order given, with the first being the outermost, and can be given
+// over multiple calls to WithMiddleware.
+//
+// Middleware sees every attempt at a request, including retries, after
+// the rate limiter. The client's credentials are added after all
+// middleware has run, so they are not visible to it.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(kc *Client) {
+		kc.middleware = append(kc.middleware, middleware...)
+	}
+}
+
+// buildChain wraps the client's own handler in its middleware.
+func (kc *Client) buildChain() Handler {
+	var handler Handler = kc.doAuthenticated
+	for i := len(kc.middleware) - 1; i >= 0; i-- {
+		handler = kc.middleware[i](handler)
+	}
+
+	return handler
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimitObserver is called after a request has waited for the
+// client's rate limiter, with how long it waited. It can be used to log
+// or measure client side throttling.
+type RateLimitObserver func(r ResourceRequest, wait time.Duration)
+
+// WithRateLimit limits the client to qps requests per second, allowing
+// bursts of up to burst requests. Long running watches are not counted.
+//
+// If WithMutatingRateLimit is also given, this limit only applies to
+// read-only requests.
+func WithRateLimit(qps float64, burst int) Option {
+	return func(kc *Client) {
+		kc.readLimiter = rate.NewLimiter(rate.Limit(qps), burst)
+	}
+}
+
+// WithMutatingRateLimit gives mutating requests (POST, PUT, PATCH and
+// DELETE) their own budget of qps requests per second, with bursts of up
+// to burst requests, separate from that given by WithRateLimit.
+func WithMutatingRateLimit(qps float64, burst int) Option {
+	return func(kc *Client) {
+		kc.writeLimiter = rate.NewLimiter(rate.Limit(qps), burst)
+	}
+}
+
+// WithRateLimitObserver sets a function to be called with the time each
+// request spent waiting for the rate limiter.
+func WithRateLimitObserver(observer RateLimitObserver) Option {
+	return func(kc *Client) {
+		kc.rateLimitObserver = observer
+	}
+}
+
+// waitForRateLimit blocks until the request is allowed by the client's
+// rate limiter, or ctx is done.
+func (kc *Client) waitForRateLimit(ctx context.Context, r ResourceRequest) error {
+	if r.IsWatch() {
+		return nil
+	}
+
+	limiter := kc.readLimiter
+	if r.IsMutating() && kc.writeLimiter != nil {
+		limiter = kc.writeLimiter
+	}
+	if limiter == nil {
+		return nil
+	}
+
+	start := time.Now()
+	err := limiter.Wait(ctx)
+	wait := time.Since(start)
+
+	kc.metrics.observeRateLimit(r, wait)
+	if kc.rateLimitObserver != nil {
+		kc.rateLimitObserver(r, wait)
+	}
+
+	return err
+}
@@ -1,9 +1,10 @@
 package client
 
 import (
-	"io"
+	"net/http"
 	"net/url"
 	"path"
+	"strings"
 
 	"github.com/EmilyShepherd/k8s-client-go/types"
 )
@@ -12,8 +13,8 @@ type ContentType string
 
 const (
 	ApplyPatchContentType ContentType = "application/apply-patch+yaml"
-	MergePatchContentType             = "application/merge-patch+json"
-	JSONContentType                   = "application/json"
+	MergePatchContentType ContentType = "application/merge-patch+json"
+	JSONContentType       ContentType = "application/json"
 )
 
 type ResourceRequest struct {
@@ -24,7 +25,18 @@ type ResourceRequest struct {
 	Name        string
 	ContentType ContentType
 	Values      url.Values
-	Body        io.Reader
+
+	// Accept is sent as the request's Accept header. If it is empty, JSON
+	// is asked for.
+	Accept string
+
+	// Body is the encoded request body. This is held as a byte slice,
+	// rather than a reader, so that the request can be retried.
+	Body []byte
+
+	// RetryPolicy overrides the client's RetryPolicy for this request, if
+	// it is set.
+	RetryPolicy *RetryPolicy
 }
 
 func (r ResourceRequest) URL() string {
@@ -46,3 +58,39 @@ func (r ResourceRequest) URL() string {
 
 	return url
 }
+
+// IsWatch returns true if this is a long running watch request
+func (r ResourceRequest) IsWatch() bool {
+	watch := r.Values.Get("watch")
+	return watch == "1" || watch == "true"
+}
+
+// IsMutating returns true if this request may change state on the
+// apiserver
+func (r ResourceRequest) IsMutating() bool {
+	switch r.Verb {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+// IsApply returns true if this is a server-side apply patch, in any
+// encoding
+func (r ResourceRequest) IsApply() bool {
+	return strings.HasPrefix(string(r.ContentType), "application/apply-patch+")
+}
+
+// IsIdempotent returns true if repeating this request has the same
+// effect as making it once, and so is safe to retry.
+func (r ResourceRequest) IsIdempotent() bool {
+	switch r.Verb {
+	case http.MethodPost:
+		return false
+	case http.MethodPatch:
+		return r.IsApply() || r.ContentType == MergePatchContentType
+	}
+
+	return true
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how the client retries requests which fail for
+// transient reasons.
+//
+// Requests are retried on connection errors, and on 429, 500, 502, 503
+// and 504 responses. If the apiserver sends a Retry-After header, it is
+// honoured, otherwise the client backs off exponentially with jitter.
+//
+// Only idempotent requests are retried after they may have reached the
+// apiserver. Non-idempotent requests (POST, and JSON patches) are only
+// retried if the connection failed before the request could be sent.
+type RetryPolicy struct {
+	// MaxRetries is the number of times a request is retried after its
+	// first attempt. Zero disables retries.
+	MaxRetries int
+
+	// InitialBackoff is the delay before the first retry, which doubles
+	// for each subsequent retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps both the exponential backoff and any Retry-After
+	// sent by the apiserver.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is a RetryPolicy suitable for most clients. Clients
+// don't retry unless they are given it, or another, with WithRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:     5,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+}
+
+// NoRetries is a RetryPolicy which never retries
+var NoRetries = RetryPolicy{}
+
+// WithRetryPolicy sets the client's default RetryPolicy, which is
+// NoRetries otherwise.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(kc *Client) {
+		kc.retryPolicy = policy
+	}
+}
+
+type retryPolicyKey struct{}
+
+// ContextWithRetryPolicy returns a context which overrides the client's
+// RetryPolicy for any request made with it.
+func ContextWithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+// retryPolicyFor returns the policy for the request, which is the first
+// of the one set on the request itself, the one set on its context, or
+// the client's default.
+func (kc *Client) retryPolicyFor(ctx context.Context, r ResourceRequest) RetryPolicy {
+	if r.RetryPolicy != nil {
+		return *r.RetryPolicy
+	}
+	if policy, ok := ctx.Value(retryPolicyKey{}).(RetryPolicy); ok {
+		return policy
+	}
+
+	return kc.retryPolicy
+}
+
+// backoff returns how long to wait before the given retry attempt
+// (counting from zero), or false if the request should not be retried.
+func (p RetryPolicy) backoff(r ResourceRequest, attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= p.MaxRetries {
+		return 0, false
+	}
+
+	if resp == nil {
+		if err == nil || !isRetryableError(err) {
+			return 0, false
+		}
+		if !r.IsIdempotent() && !isNotSent(err) {
+			return 0, false
+		}
+	} else {
+		switch resp.StatusCode {
+		case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		default:
+			return 0, false
+		}
+		if !r.IsIdempotent() {
+			return 0, false
+		}
+
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return p.cap(time.Duration(seconds) * time.Second), true
+		}
+	}
+
+	// Exponential backoff, with "equal jitter": somewhere between half and
+	// all of the full delay.
+	delay := p.cap(p.InitialBackoff << attempt)
+	if delay > 1 {
+		delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)))
+	}
+
+	return delay, true
+}
+
+func (p RetryPolicy) cap(delay time.Duration) time.Duration {
+	if p.MaxBackoff > 0 && (delay > p.MaxBackoff || delay < 0) {
+		return p.MaxBackoff
+	}
+
+	return delay
+}
+
+// isRetryableError returns true for connection level errors which may
+// succeed if tried again.
+func isRetryableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	return isNotSent(err) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// isNotSent returns true for errors which mean the request definitely
+// never reached the apiserver, so it is safe to retry even if it is not
+// idempotent.
+func isNotSent(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// sleep waits for the given duration, or until ctx is done.
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/EmilyShepherd/k8s-client-go/pkg/trace"
+)
+
+// WithTracer records a span for each call to Do, and propagates it to the
+// apiserver with the W3C traceparent header.
+func WithTracer(tracer trace.Tracer) Option {
+	return func(kc *Client) {
+		kc.tracer = tracer
+	}
+}
+
+// startSpan starts the span for a request, if the client has a tracer.
+// The returned span is nil otherwise.
+func (kc *Client) startSpan(ctx context.Context, r ResourceRequest) (context.Context, trace.Span) {
+	if kc.tracer == nil {
+		return ctx, nil
+	}
+
+	labels := requestLabels(r)
+	resource := r.GVR.Resource
+	if r.Subresource != "" {
+		resource += "/" + r.Subresource
+	}
+
+	ctx, span := kc.tracer.Start(ctx, "kubernetes "+labels[0]+" "+resource)
+	span.SetAttributes(
+		trace.String("k8s.verb", labels[0]),
+		trace.String("k8s.group", r.GVR.Group),
+		trace.String("k8s.version", r.GVR.Version),
+		trace.String("k8s.resource", r.GVR.Resource),
+		trace.String("k8s.subresource", r.Subresource),
+		trace.String("k8s.namespace", r.Namespace),
+		trace.String("k8s.name", r.Name),
+	)
+
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+func endSpan(span trace.Span, resp *http.Response, err error) {
+	if span == nil {
+		return
+	}
+
+	if resp != nil {
+		span.SetAttributes(trace.Int("http.response.status_code", resp.StatusCode))
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	span.End()
+}
+
+// injectTraceParent adds the traceparent header for the span in ctx, if
+// there is one.
+func injectTraceParent(ctx context.Context, req *http.Request) {
+	span, ok := trace.SpanFromContext(ctx)
+	if !ok {
+		return
+	}
+
+	if sc := span.SpanContext(); sc.IsValid() {
+		req.Header.Set("traceparent", sc.TraceParent())
+	}
+}
@@ -0,0 +1,241 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Warning is a single warning sent by the apiserver in a Warning header,
+// for example for the use of a deprecated API, an unknown field, or a
+// Pod Security admission violation.
+type Warning struct {
+	// Code is the warn-code, which is always 299 for the apiserver
+	Code int
+
+	// Agent is the warn-agent, which is "-" if it was not given
+	Agent string
+
+	// Text is the unquoted warn-text
+	Text string
+}
+
+// WarningHandler is called for each warning the apiserver sends. If it
+// returns an error, the request fails with that error.
+type WarningHandler interface {
+	HandleWarning(ctx context.Context, warning Warning) error
+}
+
+// WarningHandlerFunc is an adapter to allow the use of an ordinary
+// function as a WarningHandler
+type WarningHandlerFunc func(ctx context.Context, warning Warning) error
+
+func (f WarningHandlerFunc) HandleWarning(ctx context.Context, warning Warning) error {
+	return f(ctx, warning)
+}
+
+// WithWarningHandler sets the handler for warnings sent by the apiserver.
+// By default, warnings are discarded.
+func WithWarningHandler(handler WarningHandler) Option {
+	return func(kc *Client) {
+		kc.warningHandler = handler
+	}
+}
+
+// handleWarnings passes each warning on the response to the client's
+// handler, returning the first error from it.
+func (kc *Client) handleWarnings(ctx context.Context, resp *http.Response) error {
+	if kc.warningHandler == nil {
+		return nil
+	}
+
+	var firstErr error
+	for _, warning := range ParseWarningHeaders(resp.Header.Values("Warning")) {
+		if err := kc.warningHandler.HandleWarning(ctx, warning); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// ParseWarningHeaders parses RFC 7234 Warning header values, each of
+// which may contain several comma separated warnings. Malformed warnings
+// are skipped.
+func ParseWarningHeaders(headers []string) []Warning {
+	var warnings []Warning
+	for _, header := range headers {
+		for len(header) > 0 {
+			var warning Warning
+			var ok bool
+			warning, header, ok = parseWarning(header)
+			if !ok {
+				break
+			}
+			warnings = append(warnings, warning)
+		}
+	}
+
+	return warnings
+}
+
+// parseWarning parses a single warning from the start of header, of the
+// form: warn-code SP warn-agent SP warn-text [SP warn-date]. It returns
+// the rest of the header after any following comma.
+func parseWarning(header string) (Warning, string, bool) {
+	var warning Warning
+
+	header = strings.TrimLeft(header, " ,")
+	code, header, found := strings.Cut(header, " ")
+	if !found {
+		return warning, "", false
+	}
+	warning.Code, _ = strconv.Atoi(code)
+	if warning.Code < 100 || warning.Code > 999 {
+		return warning, "", false
+	}
+
+	warning.Agent, header, found = strings.Cut(header, " ")
+	if !found || !strings.HasPrefix(header, `"`) {
+		return warning, "", false
+	}
+
+	// The text is a quoted-string, in which backslash escapes the next
+	// character.
+	var text strings.Builder
+	i := 1
+	for ; i < len(header); i++ {
+		c := header[i]
+		if c == '\\' && i+1 < len(header) {
+			i++
+			text.WriteByte(header[i])
+		} else if c == '"' {
+			break
+		} else {
+			text.WriteByte(c)
+		}
+	}
+	if i == len(header) {
+		return warning, "", false
+	}
+	warning.Text = text.String()
+	header = header[i+1:]
+
+	// Skip over the optional quoted date, which may itself contain a
+	// comma, up to the next warning.
+	header = strings.TrimLeft(header, " ")
+	if strings.HasPrefix(header, `"`) {
+		if end := strings.IndexByte(header[1:], '"'); end >= 0 {
+			header = header[end+2:]
+		}
+	}
+	if next := strings.IndexByte(header, ','); next >= 0 {
+		header = header[next+1:]
+	} else {
+		header = ""
+	}
+
+	return warning, header, true
+}
+
+// WarningLogger is a WarningHandler which logs each unique warning the
+// first time it is seen.
+type WarningLogger struct {
+	logger *log.Logger
+	seen   sync.Map
+}
+
+// NewWarningLogger creates a WarningLogger which logs to the given
+// logger, or the standard logger if it is nil.
+func NewWarningLogger(logger *log.Logger) *WarningLogger {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	return &WarningLogger{logger: logger}
+}
+
+func (l *WarningLogger) HandleWarning(_ context.Context, warning Warning) error {
+	if _, seen := l.seen.LoadOrStore(warning.Text, struct{}{}); !seen {
+		l.logger.Printf("Warning: %s", warning.Text)
+	}
+
+	return nil
+}
+
+type warningCollectorKey struct{}
+
+type warningCollector struct {
+	lock     sync.Mutex
+	warnings []Warning
+}
+
+// ContextWithWarningCollector returns a context which collects the
+// warnings from any request made with it, when the client's handler is
+// CollectWarnings. They can be retrieved with WarningsFromContext.
+func ContextWithWarningCollector(ctx context.Context) context.Context {
+	return context.WithValue(ctx, warningCollectorKey{}, &warningCollector{})
+}
+
+// WarningsFromContext returns the warnings collected so far in a context
+// from ContextWithWarningCollector.
+func WarningsFromContext(ctx context.Context) []Warning {
+	collector, ok := ctx.Value(warningCollectorKey{}).(*warningCollector)
+	if !ok {
+		return nil
+	}
+
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+
+	return append([]Warning(nil), collector.warnings...)
+}
+
+// CollectWarnings is a WarningHandler which adds warnings to the
+// request's context, if it was created by ContextWithWarningCollector,
+// and otherwise discards them.
+var CollectWarnings WarningHandler = WarningHandlerFunc(func(ctx context.Context, warning Warning) error {
+	if collector, ok := ctx.Value(warningCollectorKey{}).(*warningCollector); ok {
+		collector.lock.Lock()
+		collector.warnings = append(collector.warnings, warning)
+		collector.lock.Unlock()
+	}
+
+	return nil
+})
+
+// WarningError is returned by StrictWarnings for requests which caused a
+// warning.
+type WarningError struct {
+	Warning Warning
+}
+
+func (e *WarningError) Error() string {
+	return fmt.Sprintf("apiserver warning: %s", e.Warning.Text)
+}
+
+// StrictWarnings is a WarningHandler which fails any request for which
+// the apiserver sends a warning, with a WarningError. Note that the
+// request will still have been carried out by the apiserver.
+var StrictWarnings WarningHandler = WarningHandlerFunc(func(_ context.Context, warning Warning) error {
+	return &WarningError{Warning: warning}
+})
+
+// MultiWarningHandler passes each warning to all of the given handlers in
+// turn, returning the first error.
+func MultiWarningHandler(handlers ...WarningHandler) WarningHandler {
+	return WarningHandlerFunc(func(ctx context.Context, warning Warning) error {
+		var firstErr error
+		for _, handler := range handlers {
+			if err := handler.HandleWarning(ctx, warning); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+
+		return firstErr
+	})
+}
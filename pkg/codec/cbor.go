@@ -0,0 +1,91 @@
+package codec
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/fxamacker/cbor/v2"
+	"k8s.io/apimachinery/pkg/runtime/serializer/cbor/direct"
+
+	"github.com/EmilyShepherd/k8s-client-go/pkg/stream"
+)
+
+// CBOR is the codec for application/cbor, which is supported by newer
+// apiservers for all resources, including CRDs. Objects are encoded the
+// same way the apiserver does, so it can be used for any type which can
+// be encoded as JSON.
+var CBOR Codec = cborCodec{}
+
+// selfDescribedCBOR is the head of the "self-described CBOR" tag, which
+// the apiserver uses to recognise CBOR request bodies.
+var selfDescribedCBOR = []byte{0xd9, 0xd9, 0xf7}
+
+type cborCodec struct{}
+
+func (cborCodec) MediaType() string {
+	return "application/cbor"
+}
+
+func (cborCodec) StreamMediaType() string {
+	return "application/cbor-seq"
+}
+
+// ApplyPatchMediaType implements ApplyPatcher
+func (cborCodec) ApplyPatchMediaType() string {
+	return "application/apply-patch+cbor"
+}
+
+func (cborCodec) Marshal(v any) ([]byte, error) {
+	data, err := direct.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(data, selfDescribedCBOR) {
+		return data, nil
+	}
+
+	return append(append([]byte(nil), selfDescribedCBOR...), data...), nil
+}
+
+func (cborCodec) Unmarshal(data []byte, v any) error {
+	return direct.Unmarshal(data, v)
+}
+
+func (c cborCodec) NewDecoder(r io.Reader) stream.Decoder {
+	return readAllDecoder{r: r, unmarshal: c.Unmarshal}
+}
+
+func (cborCodec) NewWatchDecoder(r io.Reader) stream.Decoder {
+	return cborWatchDecoder{decoder: cbor.NewDecoder(r)}
+}
+
+// cborWatchDecoder reads a CBOR sequence of watch events, in which each
+// event is simply the next data item.
+type cborWatchDecoder struct {
+	decoder *cbor.Decoder
+}
+
+func (d cborWatchDecoder) Decode(v any) error {
+	event, err := rawEvent(v)
+	if err != nil {
+		return err
+	}
+
+	var item cbor.RawMessage
+	if err := d.decoder.Decode(&item); err != nil {
+		return err
+	}
+
+	var wire struct {
+		Type   string          `json:"type"`
+		Object cbor.RawMessage `json:"object"`
+	}
+	if err := direct.Unmarshal(item, &wire); err != nil {
+		return err
+	}
+
+	event.Type = wire.Type
+	event.Object = wire.Object
+
+	return nil
+}
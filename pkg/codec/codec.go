@@ -0,0 +1,136 @@
+// Package codec implements the wire formats which the apiserver speaks,
+// so that an ObjectAPI can ask for something more compact than JSON and
+// decode whichever format the apiserver actually replies with.
+package codec
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"strings"
+
+	"github.com/EmilyShepherd/k8s-client-go/pkg/stream"
+)
+
+// ErrUnsupported is returned when a codec cannot encode or decode the
+// given type, for example a CRD type with the protobuf codec.
+var ErrUnsupported = errors.New("codec: type not supported")
+
+// Codec encodes and decodes objects in a single wire format
+type Codec interface {
+	// MediaType is sent as the Content-Type of request bodies encoded by
+	// the codec, and in the Accept header of requests.
+	MediaType() string
+
+	// StreamMediaType is sent in the Accept header of watch requests
+	StreamMediaType() string
+
+	// Marshal encodes v. It returns an error wrapping ErrUnsupported if
+	// the codec cannot encode it, in which case JSON should be used.
+	Marshal(v any) ([]byte, error)
+
+	// Unmarshal decodes a single object from data into v
+	Unmarshal(data []byte, v any) error
+
+	// NewDecoder returns a Decoder for a response body holding a single
+	// object.
+	NewDecoder(r io.Reader) stream.Decoder
+
+	// NewWatchDecoder returns a Decoder for the body of a watch response,
+	// which decodes each event into a *RawEvent.
+	NewWatchDecoder(r io.Reader) stream.Decoder
+}
+
+// Restricted is implemented by codecs which can only handle some types.
+// Codecs which don't implement it are assumed to handle any type.
+type Restricted interface {
+	Supports(v any) bool
+}
+
+// Supports returns true if the codec can handle v
+func Supports(c Codec, v any) bool {
+	if r, ok := c.(Restricted); ok {
+		return r.Supports(v)
+	}
+
+	return true
+}
+
+// ApplyPatcher is implemented by codecs in which server-side apply
+// patches can be sent, giving the media type to send them as. Apply
+// patches are sent as JSON for codecs which don't implement it.
+type ApplyPatcher interface {
+	ApplyPatchMediaType() string
+}
+
+// RawEvent is a watch event whose object has not yet been decoded. The
+// object should be decoded with the same codec as the event.
+type RawEvent struct {
+	Type   string
+	Object []byte
+}
+
+// Accept builds an Accept header which asks for the given codecs in
+// order of preference.
+func Accept(watch bool, codecs ...Codec) string {
+	mediaTypes := make([]string, 0, len(codecs))
+	for _, c := range codecs {
+		if watch {
+			mediaTypes = append(mediaTypes, c.StreamMediaType())
+		} else {
+			mediaTypes = append(mediaTypes, c.MediaType())
+		}
+	}
+
+	return strings.Join(mediaTypes, ", ")
+}
+
+// ForContentType returns the codec from the given list which handles the
+// Content-Type of a response. JSON is returned if none of them do.
+func ForContentType(contentType string, codecs ...Codec) Codec {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return JSON
+	}
+
+	for _, c := range codecs {
+		if mediaType == baseType(c.MediaType()) || mediaType == baseType(c.StreamMediaType()) {
+			return c
+		}
+	}
+
+	return JSON
+}
+
+func baseType(mediaType string) string {
+	base, _, _ := strings.Cut(mediaType, ";")
+	return strings.TrimSpace(base)
+}
+
+// readAllDecoder decodes a whole body at once with an Unmarshal function
+type readAllDecoder struct {
+	r         io.Reader
+	unmarshal func(data []byte, v any) error
+}
+
+func (d readAllDecoder) Decode(v any) error {
+	data, err := io.ReadAll(d.r)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return io.EOF
+	}
+
+	return d.unmarshal(data, v)
+}
+
+// rawEvent checks that a watch decoder has been given a *RawEvent
+func rawEvent(v any) (*RawEvent, error) {
+	event, ok := v.(*RawEvent)
+	if !ok {
+		return nil, errors.New("codec: watch events can only be decoded into a *RawEvent")
+	}
+
+	return event, nil
+}
@@ -0,0 +1,127 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/EmilyShepherd/k8s-client-go/pkg/stream"
+)
+
+// JSON is the codec for application/json, which every resource supports
+var JSON Codec = &jsonCodec{}
+
+// JSONOptions customises a JSON codec created by NewJSON, for example to
+// use a faster JSON library than encoding/json.
+type JSONOptions struct {
+	// Marshal encodes request bodies. It defaults to json.Marshal.
+	Marshal func(v any) ([]byte, error)
+
+	// NewDecoder creates the decoders for response bodies, and for the
+	// stream of events from a watch. It defaults to json.NewDecoder.
+	NewDecoder func(r io.Reader) stream.Decoder
+
+	// DisallowUnknownFields makes decoding fail if the data has fields
+	// which the target type does not. The decoders must then have a
+	// DisallowUnknownFields method, as those from encoding/json, jsoniter,
+	// goccy/go-json and sonic all do.
+	DisallowUnknownFields bool
+}
+
+// NewJSON creates a JSON codec with the given options
+func NewJSON(opts JSONOptions) Codec {
+	return &jsonCodec{opts: opts}
+}
+
+type jsonCodec struct {
+	opts JSONOptions
+}
+
+// strictDecoder is implemented by decoders which can reject unknown
+// fields
+type strictDecoder interface {
+	DisallowUnknownFields()
+}
+
+func (*jsonCodec) MediaType() string {
+	return "application/json"
+}
+
+func (*jsonCodec) StreamMediaType() string {
+	return "application/json"
+}
+
+func (c *jsonCodec) Marshal(v any) ([]byte, error) {
+	if c.opts.Marshal != nil {
+		return c.opts.Marshal(v)
+	}
+
+	return json.Marshal(v)
+}
+
+func (c *jsonCodec) Unmarshal(data []byte, v any) error {
+	if c.opts.NewDecoder == nil && !c.opts.DisallowUnknownFields {
+		return json.Unmarshal(data, v)
+	}
+
+	return c.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func (c *jsonCodec) NewDecoder(r io.Reader) stream.Decoder {
+	var decoder stream.Decoder
+	if c.opts.NewDecoder != nil {
+		decoder = c.opts.NewDecoder(r)
+	} else {
+		decoder = json.NewDecoder(r)
+	}
+
+	if c.opts.DisallowUnknownFields {
+		strict, ok := decoder.(strictDecoder)
+		if !ok {
+			return errDecoder{errors.New("codec: JSON decoder cannot disallow unknown fields")}
+		}
+		strict.DisallowUnknownFields()
+	}
+
+	return decoder
+}
+
+func (c *jsonCodec) NewWatchDecoder(r io.Reader) stream.Decoder {
+	return jsonWatchDecoder{decoder: c.NewDecoder(r)}
+}
+
+// jsonWatchDecoder reads the stream of JSON encoded watch events, which
+// are simply concatenated.
+type jsonWatchDecoder struct {
+	decoder stream.Decoder
+}
+
+func (d jsonWatchDecoder) Decode(v any) error {
+	event, err := rawEvent(v)
+	if err != nil {
+		return err
+	}
+
+	var wire struct {
+		Type   string          `json:"type"`
+		Object json.RawMessage `json:"object"`
+	}
+	if err := d.decoder.Decode(&wire); err != nil {
+		return err
+	}
+
+	event.Type = wire.Type
+	event.Object = wire.Object
+
+	return nil
+}
+
+// errDecoder is a Decoder which always fails
+type errDecoder struct {
+	err error
+}
+
+func (d errDecoder) Decode(any) error {
+	return d.err
+}
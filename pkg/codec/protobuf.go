@@ -0,0 +1,166 @@
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/EmilyShepherd/k8s-client-go/pkg/stream"
+)
+
+// Protobuf is the codec for application/vnd.kubernetes.protobuf. It is
+// only supported by the built-in types, from k8s.io/api, and is much
+// cheaper to decode than JSON for large lists and busy watches.
+//
+// Objects are only encoded as protobuf if their apiVersion and kind are
+// set, as the apiserver needs them to decode the object.
+var Protobuf Codec = protobufCodec{}
+
+// protobufMagic prefixes every protobuf encoded object, ahead of the
+// runtime.Unknown which wraps it.
+var protobufMagic = []byte("k8s\x00")
+
+// maxFrameSize bounds the length of a single watch frame, so that a
+// corrupt length prefix can't make us allocate without limit.
+const maxFrameSize = 256 << 20
+
+type protoMessage interface {
+	Marshal() ([]byte, error)
+	Unmarshal(data []byte) error
+}
+
+type objectKinder interface {
+	GetObjectKind() schema.ObjectKind
+}
+
+type protobufCodec struct{}
+
+func (protobufCodec) MediaType() string {
+	return "application/vnd.kubernetes.protobuf"
+}
+
+func (protobufCodec) StreamMediaType() string {
+	return "application/vnd.kubernetes.protobuf;stream=watch"
+}
+
+// Supports returns true if v has generated protobuf methods
+func (protobufCodec) Supports(v any) bool {
+	_, ok := v.(protoMessage)
+	return ok
+}
+
+func (protobufCodec) Marshal(v any) ([]byte, error) {
+	msg, ok := v.(protoMessage)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T is not a protobuf message", ErrUnsupported, v)
+	}
+	obj, ok := v.(objectKinder)
+	if !ok || obj.GetObjectKind().GroupVersionKind().Empty() {
+		return nil, fmt.Errorf("%w: %T has no apiVersion and kind", ErrUnsupported, v)
+	}
+
+	raw, err := msg.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	apiVersion, kind := obj.GetObjectKind().GroupVersionKind().ToAPIVersionAndKind()
+	unknown := runtime.Unknown{
+		TypeMeta: runtime.TypeMeta{APIVersion: apiVersion, Kind: kind},
+		Raw:      raw,
+	}
+	data, err := unknown.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(append([]byte(nil), protobufMagic...), data...), nil
+}
+
+// Unmarshal unwraps the envelope from data and decodes the object inside
+// it into v. As the object's own encoding doesn't include its apiVersion
+// and kind, they are set from the envelope.
+func (protobufCodec) Unmarshal(data []byte, v any) error {
+	msg, ok := v.(interface{ Unmarshal(data []byte) error })
+	if !ok {
+		return fmt.Errorf("%w: %T is not a protobuf message", ErrUnsupported, v)
+	}
+
+	if !bytes.HasPrefix(data, protobufMagic) {
+		return errors.New("codec: protobuf data is missing its envelope")
+	}
+
+	var unknown runtime.Unknown
+	if err := unknown.Unmarshal(data[len(protobufMagic):]); err != nil {
+		return err
+	}
+	if unknown.ContentEncoding != "" {
+		return fmt.Errorf("codec: unsupported protobuf content encoding %q", unknown.ContentEncoding)
+	}
+
+	if err := msg.Unmarshal(unknown.Raw); err != nil {
+		return err
+	}
+
+	if obj, ok := v.(objectKinder); ok && unknown.Kind != "" {
+		obj.GetObjectKind().SetGroupVersionKind(schema.FromAPIVersionAndKind(unknown.APIVersion, unknown.Kind))
+	}
+
+	return nil
+}
+
+func (c protobufCodec) NewDecoder(r io.Reader) stream.Decoder {
+	return readAllDecoder{r: r, unmarshal: c.Unmarshal}
+}
+
+func (protobufCodec) NewWatchDecoder(r io.Reader) stream.Decoder {
+	return &protobufWatchDecoder{r: bufio.NewReader(r)}
+}
+
+// protobufWatchDecoder reads a protobuf watch stream, in which each event
+// is a metav1.WatchEvent prefixed by its length as a 32 bit big endian
+// integer. Unlike objects, the events themselves have no envelope, but
+// the objects within them do.
+type protobufWatchDecoder struct {
+	r *bufio.Reader
+}
+
+func (d *protobufWatchDecoder) Decode(v any) error {
+	event, err := rawEvent(v)
+	if err != nil {
+		return err
+	}
+
+	var size uint32
+	if err := binary.Read(d.r, binary.BigEndian, &size); err != nil {
+		return err
+	}
+	if size > maxFrameSize {
+		return fmt.Errorf("codec: protobuf watch frame of %d bytes is too large", size)
+	}
+
+	frame := make([]byte, size)
+	if _, err := io.ReadFull(d.r, frame); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+
+	var wire metav1.WatchEvent
+	if err := wire.Unmarshal(frame); err != nil {
+		return err
+	}
+
+	event.Type = wire.Type
+	event.Object = wire.Object.Raw
+
+	return nil
+}
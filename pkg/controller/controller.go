@@ -1,9 +1,13 @@
 package controller
 
 import (
+	"context"
+	"sync"
+
 	"k8s.io/client-go/util/workqueue"
 
 	"github.com/EmilyShepherd/k8s-client-go/pkg/apis"
+	"github.com/EmilyShepherd/k8s-client-go/pkg/trace"
 	"github.com/EmilyShepherd/k8s-client-go/pkg/util"
 	"github.com/EmilyShepherd/k8s-client-go/types"
 )
@@ -11,17 +15,53 @@ import (
 type Controller[T any, PT types.Object[T]] struct {
 	resource *apis.ResourceCache[T, PT]
 	queue    workqueue.TypedRateLimitingInterface[string]
+
+	tracer    trace.Tracer
+	linksLock sync.Mutex
+	links     map[string][]trace.SpanContext
+}
+
+// maxLinks caps the number of watch events a reconcile span links to, if
+// a key is notified many times before it is reconciled.
+const maxLinks = 16
+
+// Option configures optional behaviour of a Controller when passed to
+// NewController or NewEmptyController.
+type Option func(*options)
+
+type options struct {
+	tracer trace.Tracer
+}
+
+// WithTracer records a span for each reconcile, linked to spans for the
+// watch events which caused the key to be queued. The event spans are
+// started in the cache's context, so they share a trace with its watch.
+func WithTracer(tracer trace.Tracer) Option {
+	return func(o *options) {
+		o.tracer = tracer
+	}
 }
 
-func NewEmptyController[T any, PT types.Object[T]](root *apis.ResourceCache[T, PT]) *Controller[T, PT] {
-	return &Controller[T, PT]{
+func NewEmptyController[T any, PT types.Object[T]](root *apis.ResourceCache[T, PT], opts ...Option) *Controller[T, PT] {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	c := &Controller[T, PT]{
 		resource: root,
 		queue:    workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[string]()),
+		tracer:   o.tracer,
+	}
+	if c.tracer != nil {
+		c.links = make(map[string][]trace.SpanContext)
 	}
+
+	return c
 }
 
-func NewController[T any, PT types.Object[T]](root *apis.ResourceCache[T, PT]) *Controller[T, PT] {
-	c := NewEmptyController[T, PT](root)
+func NewController[T any, PT types.Object[T]](root *apis.ResourceCache[T, PT], opts ...Option) *Controller[T, PT] {
+	c := NewEmptyController[T, PT](root, opts...)
 
 	root.RegisterListener(&Notifier[T, PT]{
 		Parent:  c,
@@ -32,9 +72,54 @@ func NewController[T any, PT types.Object[T]](root *apis.ResourceCache[T, PT]) *
 }
 
 func (c *Controller[T, PT]) Notify(key string) {
+	c.notifyEvent(key, "")
+}
+
+// notifyEvent queues the key, recording a span for the event which
+// caused it if the controller has a tracer.
+func (c *Controller[T, PT]) notifyEvent(key string, eventType types.EventType) {
+	if c.tracer != nil {
+		ctx := context.Background()
+		if c.resource != nil {
+			ctx = c.resource.Context()
+		}
+
+		_, span := c.tracer.Start(ctx, "kubernetes.controller.enqueue")
+		span.SetAttributes(trace.String("k8s.key", key))
+		if eventType != "" {
+			span.SetAttributes(trace.String("k8s.event.type", string(eventType)))
+		}
+		span.End()
+
+		c.linksLock.Lock()
+		if len(c.links[key]) < maxLinks {
+			c.links[key] = append(c.links[key], span.SpanContext())
+		}
+		c.linksLock.Unlock()
+	}
+
 	c.queue.AddRateLimited(key)
 }
 
+// startSpan starts the span for reconciling the key, if the controller
+// has a tracer. The returned span is nil otherwise.
+func (c *Controller[T, PT]) startSpan(key string) (context.Context, trace.Span) {
+	ctx := context.Background()
+	if c.tracer == nil {
+		return ctx, nil
+	}
+
+	c.linksLock.Lock()
+	links := c.links[key]
+	delete(c.links, key)
+	c.linksLock.Unlock()
+
+	ctx, span := c.tracer.Start(ctx, "kubernetes.controller.reconcile", links...)
+	span.SetAttributes(trace.String("k8s.key", key))
+
+	return trace.ContextWithSpan(ctx, span), span
+}
+
 func (c *Controller[T, PT]) Watches(r chan string) *Controller[T, PT] {
 	go func() {
 		for key := range r {
@@ -62,16 +147,28 @@ func (c *Controller[T, PT]) Reconcile(r Reconciller[T]) {
 			return
 		}
 
+		ctx, span := c.startSpan(key)
+
 		element, found := c.resource.Get(key)
 		if found {
-			err := r.Reconcile(element)
+			var err error
+			if cr, ok := r.(ContextReconciller[T]); ok {
+				err = cr.ReconcileContext(ctx, element)
+			} else {
+				err = r.Reconcile(element)
+			}
+
 			if err == nil {
 				// When a success occurs we have to clear the item from the rate
 				// limiter. This resets any failures or requeues it has previously had.
 				c.queue.Forget(key)
 			} else {
 				// When a failure occurs we will requeue it for a retry
-				c.Notify(key)
+				c.queue.AddRateLimited(key)
+
+				if span != nil {
+					span.RecordError(err)
+				}
 			}
 		} else {
 			// If the reconciller explictly cares about element deletions, we
@@ -82,6 +179,10 @@ func (c *Controller[T, PT]) Reconcile(r Reconciller[T]) {
 			}
 		}
 
+		if span != nil {
+			span.End()
+		}
+
 		c.queue.Done(key)
 	}
 }
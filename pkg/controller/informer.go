@@ -43,8 +43,19 @@ type Notifier[T any, PT types.Object[T]] struct {
 	Indexer Indexer[PT]
 }
 
+// eventNotifier is implemented by IndexListeners which want to know the
+// type of event which caused a key to be notified.
+type eventNotifier interface {
+	notifyEvent(key string, eventType types.EventType)
+}
+
 func (n *Notifier[T, PT]) Event(event types.Event[T, PT]) {
-	n.Parent.Notify(n.Indexer(PT(&event.Object)))
+	key := n.Indexer(PT(&event.Object))
+	if en, ok := n.Parent.(eventNotifier); ok {
+		en.notifyEvent(key, event.Type)
+	} else {
+		n.Parent.Notify(key)
+	}
 }
 
 func (n *Notifier[T, PT]) Stop() {
@@ -1,9 +1,21 @@
 package controller
 
+import (
+	"context"
+)
+
 type Reconciller[T any] interface {
 	Reconcile(T) error
 }
 
+// ContextReconciller may be implemented by a Reconciller which wants a
+// context for its work. If the controller has a tracer, the context
+// carries the reconcile's span, so that API calls made with it are
+// recorded as its children.
+type ContextReconciller[T any] interface {
+	ReconcileContext(context.Context, T) error
+}
+
 type RemoveReconciller interface {
 	Remove(namespace, name string) error
 }
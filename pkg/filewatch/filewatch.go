@@ -0,0 +1,181 @@
+// Package filewatch implements reloading of files which may change on
+// disk, such as service account tokens and CA bundles.
+package filewatch
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultInterval is how often files are re-read, regardless of whether
+// any change has been noticed.
+const DefaultInterval = time.Minute
+
+// ReloadFunc is called to (re-)read the watched file
+type ReloadFunc func() error
+
+// Watcher calls a ReloadFunc whenever a file changes.
+//
+// Rather than watching the file itself, the Watcher watches its parent
+// directory. This is required to notice changes to files mounted from
+// projected volumes, ConfigMaps and Secrets, which the kubelet updates
+// by atomically swapping a "..data" symlink rather than by writing to
+// the file: in this case, the file we are watching is never written to,
+// and a watch on it would be lost after the first swap.
+//
+// On any event in the directory the file is re-stat'd, following
+// symlinks, and is reloaded if its target, size or modification time has
+// changed. As a fallback, for filesystems which don't support inotify,
+// the file is also reloaded periodically.
+type Watcher struct {
+	filename string
+	reload   ReloadFunc
+	fsw      *fsnotify.Watcher
+	stop     chan struct{}
+	once     sync.Once
+
+	mutex sync.Mutex
+	state fileState
+	err   error
+}
+
+type fileState struct {
+	target  string
+	modTime time.Time
+	size    int64
+}
+
+func statFile(filename string) (fileState, error) {
+	target, err := filepath.EvalSymlinks(filename)
+	if err != nil {
+		return fileState{}, err
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		return fileState{}, err
+	}
+
+	return fileState{
+		target:  target,
+		modTime: info.ModTime(),
+		size:    info.Size(),
+	}, nil
+}
+
+// New calls reload once, returning its error if it fails, and then
+// calls it again whenever the file changes, or every interval. If the
+// interval is not positive, the file is only reloaded when it changes.
+func New(filename string, interval time.Duration, reload ReloadFunc) (*Watcher, error) {
+	w := &Watcher{
+		filename: filename,
+		reload:   reload,
+		stop:     make(chan struct{}),
+	}
+
+	if err := w.Reload(); err != nil {
+		return nil, err
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := fsw.Add(filepath.Dir(filename)); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+	w.fsw = fsw
+
+	go w.run(interval)
+
+	return w, nil
+}
+
+func (w *Watcher) run(interval time.Duration) {
+	// A nil channel never fires, so there is no periodic reload
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case _, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			w.reloadIfChanged()
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			w.setErr(err)
+		case <-tick:
+			w.Reload()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+func (w *Watcher) reloadIfChanged() {
+	state, err := statFile(w.filename)
+
+	w.mutex.Lock()
+	changed := err != nil || state != w.state
+	w.mutex.Unlock()
+
+	if changed {
+		w.Reload()
+	}
+}
+
+// Reload calls the ReloadFunc immediately, and records its result.
+func (w *Watcher) Reload() error {
+	state, _ := statFile(w.filename)
+	err := w.reload()
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.err = err
+	if err == nil {
+		w.state = state
+	}
+
+	return err
+}
+
+func (w *Watcher) setErr(err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.err = err
+}
+
+// LastError returns the error from the most recent attempt to reload
+// the file, or from the underlying filesystem watch, if it failed.
+func (w *Watcher) LastError() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.err
+}
+
+// Close stops watching the file.
+func (w *Watcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.stop)
+		err = w.fsw.Close()
+	})
+
+	return err
+}
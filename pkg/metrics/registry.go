@@ -0,0 +1,277 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultRegistry is an in-memory Registry which can render its metrics
+// in the Prometheus text exposition format.
+type DefaultRegistry struct {
+	lock    sync.Mutex
+	metrics map[string]*metric
+}
+
+// NewRegistry creates an empty DefaultRegistry
+func NewRegistry() *DefaultRegistry {
+	return &DefaultRegistry{
+		metrics: make(map[string]*metric),
+	}
+}
+
+type metricType string
+
+const (
+	counterType   metricType = "counter"
+	gaugeType     metricType = "gauge"
+	histogramType metricType = "histogram"
+)
+
+type metric struct {
+	lock    sync.Mutex
+	name    string
+	help    string
+	kind    metricType
+	labels  []string
+	buckets []float64
+	series  map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	value       float64
+	counts      []uint64
+	count       uint64
+}
+
+func (r *DefaultRegistry) get(name, help string, kind metricType, buckets []float64, labels []string) *metric {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if m, ok := r.metrics[name]; ok {
+		if m.kind != kind {
+			panic(fmt.Sprintf("metric %s registered as both %s and %s", name, m.kind, kind))
+		}
+		if !slices.Equal(m.labels, labels) {
+			panic(fmt.Sprintf("metric %s registered with labels %v and %v", name, m.labels, labels))
+		}
+		return m
+	}
+
+	m := &metric{
+		name:    name,
+		help:    help,
+		kind:    kind,
+		labels:  labels,
+		buckets: buckets,
+		series:  make(map[string]*series),
+	}
+	r.metrics[name] = m
+
+	return m
+}
+
+// Counter returns the counter with the given name, creating it if
+// needed. It panics if the name is already registered as another kind of
+// metric, or with different labels.
+func (r *DefaultRegistry) Counter(name, help string, labels ...string) Counter {
+	return r.get(name, help, counterType, nil, labels)
+}
+
+// Gauge is as Counter, but for a gauge
+func (r *DefaultRegistry) Gauge(name, help string, labels ...string) Gauge {
+	return r.get(name, help, gaugeType, nil, labels)
+}
+
+// Histogram is as Counter, but for a histogram with the given buckets
+func (r *DefaultRegistry) Histogram(name, help string, buckets []float64, labels ...string) Histogram {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+
+	return r.get(name, help, histogramType, sorted, labels)
+}
+
+// seriesFor returns the series for the given label values, creating it
+// if needed. It panics if the number of values doesn't match the labels.
+// The caller must hold the metric's lock.
+func (m *metric) seriesFor(labelValues []string) *series {
+	if len(labelValues) != len(m.labels) {
+		panic(fmt.Sprintf("metric %s has %d labels, but was given %d values", m.name, len(m.labels), len(labelValues)))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+	s, ok := m.series[key]
+	if !ok {
+		s = &series{
+			labelValues: append([]string(nil), labelValues...),
+			counts:      make([]uint64, len(m.buckets)),
+		}
+		m.series[key] = s
+	}
+
+	return s
+}
+
+func (m *metric) Add(value float64, labelValues ...string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.seriesFor(labelValues).value += value
+}
+
+func (m *metric) Observe(value float64, labelValues ...string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	s := m.seriesFor(labelValues)
+	s.value += value
+	s.count++
+	for i, bound := range m.buckets {
+		if value <= bound {
+			s.counts[i]++
+		}
+	}
+}
+
+// WriteTo writes all metrics to w in the Prometheus text format.
+func (r *DefaultRegistry) WriteTo(w io.Writer) (int64, error) {
+	r.lock.Lock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	r.lock.Unlock()
+	sort.Strings(names)
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	for _, name := range names {
+		r.lock.Lock()
+		m := r.metrics[name]
+		r.lock.Unlock()
+
+		m.write(cw)
+	}
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+
+	return cw.n, cw.err
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format, so that
+// the registry can be used directly as a /metrics handler.
+func (r *DefaultRegistry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.WriteTo(w)
+}
+
+func (m *metric) write(w *countingWriter) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	keys := make([]string, 0, len(m.series))
+	for key := range m.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	w.printf("# HELP %s %s\n", m.name, escapeHelp(m.help))
+	w.printf("# TYPE %s %s\n", m.name, m.kind)
+
+	for _, key := range keys {
+		s := m.series[key]
+		labels := formatLabels(m.labels, s.labelValues, "")
+
+		if m.kind != histogramType {
+			w.printf("%s%s %s\n", m.name, labels, formatFloat(s.value))
+			continue
+		}
+
+		for i, bound := range m.buckets {
+			w.printf("%s_bucket%s %d\n", m.name, formatLabels(m.labels, s.labelValues, formatFloat(bound)), s.counts[i])
+		}
+		w.printf("%s_bucket%s %d\n", m.name, formatLabels(m.labels, s.labelValues, "+Inf"), s.count)
+		w.printf("%s_sum%s %s\n", m.name, labels, formatFloat(s.value))
+		w.printf("%s_count%s %d\n", m.name, labels, s.count)
+	}
+}
+
+func formatLabels(names, values []string, le string) string {
+	if len(names) == 0 && le == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(escapeLabel(values[i]))
+		b.WriteByte('"')
+	}
+	if le != "" {
+		if len(names) > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(`le="`)
+		b.WriteString(le)
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+
+	return b.String()
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) printf(format string, args ...any) {
+	if c.err != nil {
+		return
+	}
+
+	n, err := fmt.Fprintf(c.w, format, args...)
+	c.n += int64(n)
+	c.err = err
+}
@@ -0,0 +1,44 @@
+// Package metrics defines a minimal metrics registry, which the client
+// uses to record its apiserver traffic, and a default implementation of
+// it which can be exposed in the Prometheus text format.
+//
+// The interfaces are deliberately small, so that they can be adapted to
+// other metrics libraries, such as client_golang or OpenTelemetry,
+// without this module depending on them.
+package metrics
+
+// Registry creates named metrics. Calling a method more than once with
+// the same name returns the same metric.
+//
+// As registration mistakes are programming errors, implementations may
+// panic if a name is registered again as a different kind of metric, or
+// with different labels, as client_golang's MustRegister does.
+type Registry interface {
+	Counter(name, help string, labels ...string) Counter
+	Gauge(name, help string, labels ...string) Gauge
+	Histogram(name, help string, buckets []float64, labels ...string) Histogram
+}
+
+// Counter is a value which only goes up
+type Counter interface {
+	// Add adds the given value, which must not be negative, to the series
+	// with the given label values. There must be a value for each label.
+	Add(value float64, labelValues ...string)
+}
+
+// Gauge is a value which can go up and down
+type Gauge interface {
+	// Add adds the given value, which may be negative, to the series with
+	// the given label values. There must be a value for each label.
+	Add(value float64, labelValues ...string)
+}
+
+// Histogram counts observations into buckets
+type Histogram interface {
+	// Observe records a value in the series with the given label values.
+	// There must be a value for each label.
+	Observe(value float64, labelValues ...string)
+}
+
+// DefBuckets are the default histogram buckets for latencies, in seconds
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
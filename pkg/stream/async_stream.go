@@ -1,6 +1,7 @@
 package stream
 
 import (
+	"context"
 	"io"
 	"sync"
 )
@@ -14,18 +15,23 @@ import (
 type AsyncStream[T any] struct {
 	stream  Stream[T]
 	result  chan T
+	done    chan struct{}
 	lock    sync.RWMutex
 	stopped bool
 	err     error
 }
 
-func NewAsyncStream[T any](stream Stream[T]) *AsyncStream[T] {
+// NewAsyncStream starts reading from the given stream in the background.
+// The stream is stopped when ctx is cancelled, in which case Error()
+// will report the context's error.
+func NewAsyncStream[T any](ctx context.Context, stream Stream[T]) *AsyncStream[T] {
 	sd := &AsyncStream[T]{
 		stream: stream,
 		result: make(chan T),
+		done:   make(chan struct{}),
 	}
 
-	go sd.run()
+	go sd.run(ctx)
 
 	return sd
 }
@@ -37,28 +43,43 @@ func (sd *AsyncStream[T]) Stopped() bool {
 	return sd.stopped
 }
 
-func (sd *AsyncStream[T]) run() {
-	for {
-		if sd.stopped {
-			return
+func (sd *AsyncStream[T]) run(ctx context.Context) {
+	// The result channel is only ever closed here, once we know nothing
+	// else will be sent down it.
+	defer close(sd.result)
+
+	// Closing the underlying stream is the only way to unblock a pending
+	// call to Next(), so we do that as soon as the context is done.
+	go func() {
+		select {
+		case <-ctx.Done():
+			sd.stop(ctx.Err())
+		case <-sd.done:
 		}
+	}()
 
+	for {
 		result, err := sd.stream.Next()
-
-		if sd.stopped {
+		if err != nil {
+			sd.stop(err)
 			return
 		}
 
-		if err != nil {
-			sd.err = err
-			sd.Stop()
-		} else {
-			sd.result <- result
+		select {
+		case sd.result <- result:
+		case <-sd.done:
+			return
 		}
 	}
 }
 
 func (sd *AsyncStream[T]) Stop() {
+	sd.stop(nil)
+}
+
+// stop shuts down the stream, recording err as the reason if it is the
+// first thing to do so.
+func (sd *AsyncStream[T]) stop(err error) {
 	sd.lock.Lock()
 	defer sd.lock.Unlock()
 
@@ -66,21 +87,32 @@ func (sd *AsyncStream[T]) Stop() {
 		return
 	}
 
-	close(sd.result)
+	// Once this is set, the main run loop will ignore any further events
+	// and will exit.
+	sd.stopped = true
+	sd.err = err
+	close(sd.done)
 
 	// If the stream we've been given can be closed, we'll call that as
 	// part of the shutdown.
 	if closer, ok := sd.stream.(io.Closer); ok {
 		closer.Close()
 	}
-
-	// Once this is set, the main run loop will ignore any further events
-	// and will exit.
-	sd.stopped = true
 }
 
+// Next blocks until the next object is available. Once the stream has
+// stopped, it returns the error which stopped it, or io.EOF if it was
+// stopped explicitly.
 func (sd *AsyncStream[T]) Next() (T, error) {
-	return <-sd.result, sd.err
+	result, ok := <-sd.result
+	if !ok {
+		if err := sd.Error(); err != nil {
+			return result, err
+		}
+		return result, io.EOF
+	}
+
+	return result, nil
 }
 
 func (sd *AsyncStream[T]) ResultChan() <-chan T {
@@ -88,5 +120,8 @@ func (sd *AsyncStream[T]) ResultChan() <-chan T {
 }
 
 func (sd *AsyncStream[T]) Error() error {
+	sd.lock.RLock()
+	defer sd.lock.RUnlock()
+
 	return sd.err
 }
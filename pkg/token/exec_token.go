@@ -0,0 +1,226 @@
+package token
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// execRefreshSkew is how long before a credential's expiry the plugin is
+// run again to fetch a new one.
+const execRefreshSkew = 30 * time.Second
+
+const execCredentialAPIVersion = "client.authentication.k8s.io/v1"
+
+// ExecConfig describes a client-go credential plugin, as found in the
+// "exec" section of a kubeconfig user.
+//
+// See https://kubernetes.io/docs/reference/access-authn-authz/authentication/#client-go-credential-plugins
+type ExecConfig struct {
+	Command            string       `json:"command"`
+	Args               []string     `json:"args,omitempty"`
+	Env                []ExecEnvVar `json:"env,omitempty"`
+	APIVersion         string       `json:"apiVersion,omitempty"`
+	ProvideClusterInfo bool         `json:"provideClusterInfo,omitempty"`
+
+	// Cluster is passed to the plugin if ProvideClusterInfo is set. It is
+	// not read from the kubeconfig's exec section, but filled in from the
+	// cluster which the plugin is being used for.
+	Cluster *ExecCluster `json:"-"`
+}
+
+type ExecEnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type ExecCluster struct {
+	Server                   string `json:"server"`
+	TLSServerName            string `json:"tls-server-name,omitempty"`
+	InsecureSkipTLSVerify    bool   `json:"insecure-skip-tls-verify,omitempty"`
+	CertificateAuthorityData []byte `json:"certificate-authority-data,omitempty"`
+}
+
+type execCredential struct {
+	APIVersion string                `json:"apiVersion"`
+	Kind       string                `json:"kind"`
+	Spec       execCredentialSpec    `json:"spec"`
+	Status     *execCredentialStatus `json:"status,omitempty"`
+}
+
+type execCredentialSpec struct {
+	Interactive bool         `json:"interactive"`
+	Cluster     *ExecCluster `json:"cluster,omitempty"`
+}
+
+type execCredentialStatus struct {
+	ExpirationTimestamp   *time.Time `json:"expirationTimestamp,omitempty"`
+	Token                 string     `json:"token,omitempty"`
+	ClientCertificateData string     `json:"clientCertificateData,omitempty"`
+	ClientKeyData         string     `json:"clientKeyData,omitempty"`
+}
+
+// ExecToken is a TokenProvider which gets its credentials by running an
+// external credential plugin, such as those used for EKS, GKE, AKS or
+// kubelogin.
+//
+// The plugin's response is cached until shortly before it expires. If
+// the plugin returns a client certificate, ExecToken can also be used as
+// a [cert.CertificateProvider].
+type ExecToken struct {
+	config ExecConfig
+
+	mutex  sync.Mutex
+	status *execCredentialStatus
+	cert   *tls.Certificate
+	err    error
+}
+
+// NewExecToken creates an ExecToken for the given plugin configuration,
+// running the plugin once to check that it works.
+func NewExecToken(config ExecConfig) (*ExecToken, error) {
+	if config.APIVersion == "" {
+		config.APIVersion = execCredentialAPIVersion
+	}
+
+	execToken := ExecToken{config: config}
+
+	execToken.mutex.Lock()
+	defer execToken.mutex.Unlock()
+	if err := execToken.refresh(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return &execToken, nil
+}
+
+// Token returns the token from the plugin, running it again if the
+// cached credential is about to expire. If that fails, but the cached
+// token has not actually expired yet, it is still returned.
+func (t *ExecToken) Token(ctx context.Context) (string, time.Time, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.refreshIfExpired(ctx)
+
+	if t.status == nil {
+		return "", time.Time{}, t.err
+	}
+
+	var expiry time.Time
+	if t.status.ExpirationTimestamp != nil {
+		expiry = *t.status.ExpirationTimestamp
+		if t.err != nil && !time.Now().Before(expiry) {
+			return "", time.Time{}, t.err
+		}
+	}
+
+	return t.status.Token, expiry, nil
+}
+
+// Invalidate drops the cached credentials, so that the plugin is run
+// again the next time they are needed.
+func (t *ExecToken) Invalidate() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.status = nil
+	t.cert = nil
+}
+
+// Certificate returns the client certificate from the plugin, if it
+// provided one. If it didn't, an empty certificate is returned so that
+// none is presented to the server. As with Token, a cached certificate
+// which has not expired is still returned if running the plugin fails.
+func (t *ExecToken) Certificate() (*tls.Certificate, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.refreshIfExpired(context.Background())
+
+	if t.status == nil {
+		return nil, t.err
+	}
+	if t.cert == nil {
+		return &tls.Certificate{}, nil
+	}
+
+	if t.err != nil && t.status.ExpirationTimestamp != nil && !time.Now().Before(*t.status.ExpirationTimestamp) {
+		return nil, t.err
+	}
+
+	return t.cert, nil
+}
+
+func (t *ExecToken) refreshIfExpired(ctx context.Context) {
+	if t.status != nil {
+		expiry := t.status.ExpirationTimestamp
+		if expiry == nil || time.Now().Add(execRefreshSkew).Before(*expiry) {
+			return
+		}
+	}
+
+	t.err = t.refresh(ctx)
+}
+
+// refresh runs the plugin, and replaces the cached credentials with its
+// response. The caller must hold the mutex.
+func (t *ExecToken) refresh(ctx context.Context) error {
+	request := execCredential{
+		APIVersion: t.config.APIVersion,
+		Kind:       "ExecCredential",
+	}
+	if t.config.ProvideClusterInfo {
+		request.Spec.Cluster = t.config.Cluster
+	}
+	info, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, t.config.Command, t.config.Args...)
+	cmd.Env = append(os.Environ(), "KUBERNETES_EXEC_INFO="+string(info))
+	for _, env := range t.config.Env {
+		cmd.Env = append(cmd.Env, env.Name+"="+env.Value)
+	}
+	cmd.Stderr = os.Stderr
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("exec plugin %s failed: %w", t.config.Command, err)
+	}
+
+	var response execCredential
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return fmt.Errorf("exec plugin %s returned invalid ExecCredential: %w", t.config.Command, err)
+	}
+	if response.Kind != "ExecCredential" || response.APIVersion != t.config.APIVersion {
+		return fmt.Errorf("exec plugin %s returned %s %s, expected ExecCredential %s", t.config.Command, response.APIVersion, response.Kind, t.config.APIVersion)
+	}
+	if response.Status == nil {
+		return fmt.Errorf("exec plugin %s returned no status", t.config.Command)
+	}
+
+	var cert *tls.Certificate
+	if response.Status.ClientCertificateData != "" || response.Status.ClientKeyData != "" {
+		pair, err := tls.X509KeyPair([]byte(response.Status.ClientCertificateData), []byte(response.Status.ClientKeyData))
+		if err != nil {
+			return fmt.Errorf("exec plugin %s returned invalid client certificate: %w", t.config.Command, err)
+		}
+		cert = &pair
+	} else if response.Status.Token == "" {
+		return fmt.Errorf("exec plugin %s returned neither a token nor a client certificate", t.config.Command)
+	}
+
+	t.status = response.Status
+	t.cert = cert
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package token
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// writePlugin writes a shell script which stands in for a credential
+// plugin. It prints the given response the first time it is run, and
+// fails every time after that.
+func writePlugin(t *testing.T, response string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("exec plugin tests need a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "response.json"), []byte(response), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	script := `#!/bin/sh
+cd "$(dirname "$0")"
+if [ -e ran ]; then
+	echo "plugin failed" >&2
+	exit 1
+fi
+touch ran
+cat response.json
+`
+	plugin := filepath.Join(dir, "plugin.sh")
+	if err := os.WriteFile(plugin, []byte(script), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	return plugin
+}
+
+func TestExecToken(t *testing.T) {
+	plugin := writePlugin(t, `{
+		"apiVersion": "client.authentication.k8s.io/v1",
+		"kind": "ExecCredential",
+		"status": {"token": "abc"}
+	}`)
+
+	tp, err := NewExecToken(ExecConfig{Command: plugin})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, expiry, err := tp.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "abc" || !expiry.IsZero() {
+		t.Errorf("Token() = %q, %v, want %q with no expiry", token, expiry, "abc")
+	}
+
+	cert, err := tp.Certificate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cert.Certificate) != 0 {
+		t.Errorf("Certificate() returned a certificate for a token only plugin")
+	}
+}
+
+func TestExecTokenRefreshFailure(t *testing.T) {
+	expiry := time.Now().Add(execRefreshSkew / 2).UTC().Format(time.RFC3339)
+	plugin := writePlugin(t, `{
+		"apiVersion": "client.authentication.k8s.io/v1",
+		"kind": "ExecCredential",
+		"status": {"token": "abc", "expirationTimestamp": "`+expiry+`"}
+	}`)
+
+	tp, err := NewExecToken(ExecConfig{Command: plugin})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The credential is within the refresh skew, so the plugin is run
+	// again, and fails, but the cached token has not yet expired.
+	token, _, err := tp.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() returned %v, want the cached token", err)
+	}
+	if token != "abc" {
+		t.Errorf("Token() = %q, want %q", token, "abc")
+	}
+
+	cert, err := tp.Certificate()
+	if err != nil {
+		t.Fatalf("Certificate() returned %v, want an empty certificate", err)
+	}
+	if len(cert.Certificate) != 0 {
+		t.Errorf("Certificate() returned a certificate for a token only plugin")
+	}
+}
+
+func TestExecTokenInvalidResponse(t *testing.T) {
+	plugin := writePlugin(t, `{"apiVersion": "v1", "kind": "Pod"}`)
+
+	if _, err := NewExecToken(ExecConfig{Command: plugin}); err == nil {
+		t.Error("NewExecToken() accepted a response which is not an ExecCredential")
+	}
+}
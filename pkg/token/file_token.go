@@ -1,10 +1,12 @@
 package token
 
 import (
-	"io/ioutil"
+	"context"
+	"os"
 	"sync"
+	"time"
 
-	"github.com/fsnotify/fsnotify"
+	"github.com/EmilyShepherd/k8s-client-go/pkg/filewatch"
 )
 
 // FileToken is a TokenProvider for a token which is backed by a file.
@@ -14,62 +16,85 @@ import (
 // This is typically used for in-cluster service account tokens, which
 // Kubernetes mounts into the pod at
 // /var/run/secrets/kubernets.io/serviceaccount/token, and will change
-// this file if and when the token expires and is reissued.
+// this file if and when the token expires and is reissued. As this is a
+// projected volume, the kubelet does this by swapping a symlink in the
+// parent directory rather than by writing to the file; see
+// [filewatch.Watcher] for how this is handled.
 type FileToken struct {
-	mutex sync.RWMutex
-	token string
+	filename string
+	watcher  *filewatch.Watcher
+	mutex    sync.RWMutex
+	token    string
+	expiry   time.Time
+	err      error
 }
 
+// NewFileToken reads the token from the given file, and then watches it
+// for changes, re-reading it every filewatch.DefaultInterval regardless.
 func NewFileToken(filename string) (*FileToken, error) {
+	return NewFileTokenWithInterval(filename, filewatch.DefaultInterval)
+}
 
-	value, err := ioutil.ReadFile(filename)
-	if err != nil {
-		return nil, err
-	}
-
-	fileToken := FileToken{
-		token: string(value),
+// NewFileTokenWithInterval is as NewFileToken, but with a custom
+// interval for the periodic re-read. An interval of zero disables it.
+func NewFileTokenWithInterval(filename string, interval time.Duration) (*FileToken, error) {
+	fileToken := &FileToken{
+		filename: filename,
 	}
 
-	watcher, err := fsnotify.NewWatcher()
+	watcher, err := filewatch.New(filename, interval, fileToken.reload)
 	if err != nil {
 		return nil, err
 	}
+	fileToken.watcher = watcher
 
-	go func() {
-		for {
-			select {
-			case event, ok := <-watcher.Events:
-				if !ok {
-					return
-				}
-				if event.Op&fsnotify.Write == fsnotify.Write {
-					value, err := ioutil.ReadFile(filename)
-					if err == nil {
-						fileToken.mutex.Lock()
-						fileToken.token = string(value)
-						fileToken.mutex.Unlock()
-					}
-				}
-			case _, ok := <-watcher.Errors:
-				if !ok {
-					return
-				}
-			}
-		}
-	}()
-
-	err = watcher.Add(filename)
-	if err != nil {
-		return nil, err
+	return fileToken, nil
+}
+
+// reload re-reads the token from the file. If this fails, the previous
+// token is kept, and the error is reported by LastError().
+func (t *FileToken) reload() error {
+	value, err := os.ReadFile(t.filename)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.err = err
+	if err == nil {
+		t.token = string(value)
+		t.expiry, _ = JWTExpiry(t.token)
 	}
 
-	return &fileToken, nil
+	return err
 }
 
-func (t *FileToken) Token() string {
+// Token returns the token most recently read from the file, along with
+// its expiry if it is a JWT. If the last attempt to re-read the file
+// failed, the error is only returned if there is no token, or it has
+// expired.
+func (t *FileToken) Token(context.Context) (string, time.Time, error) {
 	t.mutex.RLock()
 	defer t.mutex.RUnlock()
 
-	return t.token
+	if t.err != nil && (t.token == "" || (!t.expiry.IsZero() && !time.Now().Before(t.expiry))) {
+		return "", time.Time{}, t.err
+	}
+
+	return t.token, t.expiry, nil
+}
+
+// Invalidate re-reads the token from the file immediately.
+func (t *FileToken) Invalidate() {
+	t.watcher.Reload()
+}
+
+// LastError returns the error from the last attempt to reload the token,
+// or from watching the file, if either failed.
+func (t *FileToken) LastError() error {
+	return t.watcher.LastError()
+}
+
+// Close stops watching the file. The last token read remains available.
+func (t *FileToken) Close() error {
+	return t.watcher.Close()
 }
@@ -0,0 +1,73 @@
+package token
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RefreshingToken is a TokenProvider which caches the token from another
+// provider, and asks it for a new one shortly before the cached token
+// expires.
+//
+// The expiry is taken from the underlying provider if it reports one,
+// otherwise from the token's "exp" claim if it is a JWT. Tokens with no
+// known expiry are cached until Invalidate() is called.
+type RefreshingToken struct {
+	source TokenProvider
+	skew   time.Duration
+
+	mutex  sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// NewRefreshingToken wraps the given provider, refreshing the token when
+// it is within skew of expiring.
+func NewRefreshingToken(source TokenProvider, skew time.Duration) *RefreshingToken {
+	return &RefreshingToken{
+		source: source,
+		skew:   skew,
+	}
+}
+
+func (t *RefreshingToken) Token(ctx context.Context) (string, time.Time, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.token != "" && (t.expiry.IsZero() || time.Now().Add(t.skew).Before(t.expiry)) {
+		return t.token, t.expiry, nil
+	}
+
+	token, expiry, err := t.source.Token(ctx)
+	if err != nil {
+		// If we were refreshing early, the old token is still good for
+		// now, so we can carry on using it and try again next time.
+		if t.token != "" && time.Now().Before(t.expiry) {
+			return t.token, t.expiry, nil
+		}
+		return "", time.Time{}, err
+	}
+
+	if expiry.IsZero() {
+		expiry, _ = JWTExpiry(token)
+	}
+
+	t.token = token
+	t.expiry = expiry
+
+	return t.token, t.expiry, nil
+}
+
+// Invalidate drops the cached token, and invalidates the underlying
+// provider if it supports it.
+func (t *RefreshingToken) Invalidate() {
+	t.mutex.Lock()
+	t.token = ""
+	t.expiry = time.Time{}
+	t.mutex.Unlock()
+
+	if invalidator, ok := t.source.(Invalidator); ok {
+		invalidator.Invalidate()
+	}
+}
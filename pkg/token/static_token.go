@@ -1,5 +1,10 @@
 package token
 
+import (
+	"context"
+	"time"
+)
+
 // StaticToken is a TokenProvider wrapper for a static token
 type StaticToken struct {
 	token string
@@ -9,6 +14,7 @@ func NewStaticToken(token string) (*StaticToken, error) {
 	return &StaticToken{token: token}, nil
 }
 
-func (t *StaticToken) Token() string {
-	return t.token
+func (t *StaticToken) Token(context.Context) (string, time.Time, error) {
+	expiry, _ := JWTExpiry(t.token)
+	return t.token, expiry, nil
 }
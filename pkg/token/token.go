@@ -1,5 +1,10 @@
 package token
 
+import (
+	"context"
+	"time"
+)
+
 // TokenProvider is a generic interface for a service that provides the
 // auth token for the client to use
 type TokenProvider interface {
@@ -7,5 +12,24 @@ type TokenProvider interface {
 	// Retrieves the current token at the time - this may return a fixed
 	// or cached value, or it may go and do some work to acquire the
 	// latest valid token.
-	Token() string
+	//
+	// The returned time is when the token expires, or the zero time if
+	// this is not known. An error is returned if no valid token could be
+	// acquired.
+	Token(ctx context.Context) (string, time.Time, error)
+}
+
+// Invalidator may be implemented by a TokenProvider which caches its
+// token. The client calls Invalidate when the apiserver rejects a token,
+// so that the next call to Token() fetches a fresh one.
+type Invalidator interface {
+	Invalidate()
+}
+
+// TokenFunc is an adapter to allow the use of an ordinary function as a
+// TokenProvider
+type TokenFunc func(ctx context.Context) (string, time.Time, error)
+
+func (f TokenFunc) Token(ctx context.Context) (string, time.Time, error) {
+	return f(ctx)
 }
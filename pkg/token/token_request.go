@@ -0,0 +1,90 @@
+package token
+
+import (
+	"context"
+	"time"
+
+	authv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/EmilyShepherd/k8s-client-go/types"
+)
+
+// tokenRequestRefreshSkew is how long before a requested token expires
+// that a new one is requested.
+const tokenRequestRefreshSkew = time.Minute
+
+// TokenRequestAPI is the ObjectAPI used by TokenRequestProvider. It
+// should be an API for the "serviceaccounts" resource, typed as a
+// TokenRequest, for example:
+//
+//	apis.NewObjectAPI[authv1.TokenRequest](kc, types.GroupVersionResource{
+//		Version:  "v1",
+//		Resource: "serviceaccounts",
+//	})
+type TokenRequestAPI = types.ObjectAPI[authv1.TokenRequest, *authv1.TokenRequest]
+
+// TokenRequestProvider is a TokenProvider which mints short lived,
+// audience scoped tokens for a service account, using the TokenRequest
+// API (the serviceaccounts/{name}/token subresource).
+//
+// Tokens are cached until shortly before they expire. As well as being
+// used to call other services which trust the cluster's tokens, it can
+// be given to client.NewClient, or Client.WithTokenProvider, to act as
+// that service account.
+type TokenRequestProvider struct {
+	api            TokenRequestAPI
+	namespace      string
+	serviceAccount string
+	spec           authv1.TokenRequestSpec
+	cache          *RefreshingToken
+}
+
+// NewTokenRequestProvider creates a TokenRequestProvider for the given
+// service account. If no audiences are given, the apiserver's own
+// audience is used. If expirationSeconds is zero, the apiserver's
+// default is used.
+func NewTokenRequestProvider(api TokenRequestAPI, namespace, serviceAccount string, audiences []string, expirationSeconds int64) *TokenRequestProvider {
+	provider := &TokenRequestProvider{
+		api:            api.Subresource("token"),
+		namespace:      namespace,
+		serviceAccount: serviceAccount,
+		spec: authv1.TokenRequestSpec{
+			Audiences: audiences,
+		},
+	}
+	if expirationSeconds != 0 {
+		provider.spec.ExpirationSeconds = &expirationSeconds
+	}
+	provider.cache = NewRefreshingToken(TokenFunc(provider.request), tokenRequestRefreshSkew)
+
+	return provider
+}
+
+// request asks the apiserver for a new token
+func (p *TokenRequestProvider) request(ctx context.Context) (string, time.Time, error) {
+	result, err := p.api.Create(ctx, p.namespace, authv1.TokenRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      p.serviceAccount,
+			Namespace: p.namespace,
+		},
+		Spec: p.spec,
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return result.Status.Token, result.Status.ExpirationTimestamp.Time, nil
+}
+
+// Token returns the cached token, requesting a new one if it is about to
+// expire.
+func (p *TokenRequestProvider) Token(ctx context.Context) (string, time.Time, error) {
+	return p.cache.Token(ctx)
+}
+
+// Invalidate drops the cached token, so that a new one is requested the
+// next time it is needed.
+func (p *TokenRequestProvider) Invalidate() {
+	p.cache.Invalidate()
+}
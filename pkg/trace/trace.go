@@ -0,0 +1,77 @@
+// Package trace defines a minimal tracing interface, which the client and
+// controllers use to record spans for API calls and reconciles.
+//
+// The interfaces are modelled on OpenTelemetry's, so that they can be
+// implemented by a thin adapter over an OpenTelemetry tracer, without
+// this module depending on it. Span contexts are propagated to the
+// apiserver using the W3C traceparent header.
+package trace
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+)
+
+// Tracer creates spans
+type Tracer interface {
+	// Start starts a span with the given name, as a child of any span in
+	// ctx, and linked to the given span contexts. The returned context
+	// should be used for any work done as part of the span.
+	Start(ctx context.Context, name string, links ...SpanContext) (context.Context, Span)
+}
+
+// Span is a single operation within a trace
+type Span interface {
+	SpanContext() SpanContext
+	SetAttributes(attributes ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Attribute is a key value pair describing a span
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// String returns a string valued Attribute
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int returns an integer valued Attribute
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// SpanContext identifies a span, as carried by the W3C traceparent
+// header.
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Flags   byte
+}
+
+// IsValid returns true if the trace and span IDs are both set
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// TraceParent returns the W3C traceparent header value for the span
+func (sc SpanContext) TraceParent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", hex.EncodeToString(sc.TraceID[:]), hex.EncodeToString(sc.SpanID[:]), sc.Flags)
+}
+
+type spanKey struct{}
+
+// ContextWithSpan returns a context carrying the given span
+func ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span in ctx, if there is one
+func SpanFromContext(ctx context.Context) (Span, bool) {
+	span, ok := ctx.Value(spanKey{}).(Span)
+	return span, ok
+}
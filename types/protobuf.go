@@ -0,0 +1,81 @@
+package types
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var errInvalidProtobuf = errors.New("invalid protobuf encoding")
+
+// Unmarshal decodes a list from its protobuf encoding, for built-in types
+// whose items have generated protobuf methods. Every built-in list has
+// the same shape: its ListMeta in field 1, and its items in field 2.
+func (l *List[T, PT]) Unmarshal(data []byte) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errInvalidProtobuf
+		}
+		data = data[n:]
+
+		field, wireType := key>>3, key&7
+		if wireType != 2 || (field != 1 && field != 2) {
+			var err error
+			if data, err = skipProtobufField(data, wireType); err != nil {
+				return err
+			}
+			continue
+		}
+
+		size, n := binary.Uvarint(data)
+		if n <= 0 || size > uint64(len(data)-n) {
+			return errInvalidProtobuf
+		}
+		value := data[n : n+int(size)]
+		data = data[n+int(size):]
+
+		if field == 1 {
+			if err := l.ListMeta.Unmarshal(value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var item T
+		msg, ok := any(PT(&item)).(interface{ Unmarshal(data []byte) error })
+		if !ok {
+			return fmt.Errorf("%T is not a protobuf message", item)
+		}
+		if err := msg.Unmarshal(value); err != nil {
+			return err
+		}
+		l.Items = append(l.Items, item)
+	}
+
+	return nil
+}
+
+// skipProtobufField skips over the value of a field which we don't know
+func skipProtobufField(data []byte, wireType uint64) ([]byte, error) {
+	switch wireType {
+	case 0:
+		if _, n := binary.Uvarint(data); n > 0 {
+			return data[n:], nil
+		}
+	case 1:
+		if len(data) >= 8 {
+			return data[8:], nil
+		}
+	case 2:
+		if size, n := binary.Uvarint(data); n > 0 && size <= uint64(len(data)-n) {
+			return data[n+int(size):], nil
+		}
+	case 5:
+		if len(data) >= 4 {
+			return data[4:], nil
+		}
+	}
+
+	return nil, errInvalidProtobuf
+}
@@ -0,0 +1,127 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// String encodes the selector in the apiserver's label selector syntax
+func (s LabelSelector) String() string {
+	switch s.Operator {
+	case Exists:
+		return s.Label
+	case DoesNotExist:
+		return "!" + s.Label
+	case In, NotIn:
+		return s.Label + " " + s.Operator + " (" + strings.Join(s.Values, ",") + ")"
+	case "", Equals, "==":
+		return s.Label + "=" + s.Value
+	default:
+		return s.Label + s.Operator + s.Value
+	}
+}
+
+// EncodeLabelSelector encodes the selectors as a single labelSelector
+// query parameter, such as "a=b,c!=d,e in (x,y),!f". It returns an empty
+// string if there are no selectors.
+func EncodeLabelSelector(selectors []LabelSelector) string {
+	requirements := make([]string, len(selectors))
+	for i, selector := range selectors {
+		requirements[i] = selector.String()
+	}
+
+	return strings.Join(requirements, ",")
+}
+
+// ErrNilLabelSelector is returned by LabelSelectorFromMeta for a nil
+// selector, which matches nothing, as there are no LabelSelectors which
+// are equivalent to it.
+var ErrNilLabelSelector = errors.New("label selector is nil, which matches nothing")
+
+// LabelSelectorFromMeta converts a metav1.LabelSelector, as found in the
+// spec of a Deployment or Job, to the equivalent LabelSelectors. An empty
+// selector matches every object.
+func LabelSelectorFromMeta(selector *metav1.LabelSelector) ([]LabelSelector, error) {
+	if selector == nil {
+		return nil, ErrNilLabelSelector
+	}
+
+	selectors := make([]LabelSelector, 0, len(selector.MatchLabels)+len(selector.MatchExpressions))
+
+	// Sort matchLabels so that the encoded selector is stable
+	labels := make([]string, 0, len(selector.MatchLabels))
+	for label := range selector.MatchLabels {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		selectors = append(selectors, LabelSelector{
+			Label:    label,
+			Value:    selector.MatchLabels[label],
+			Operator: Equals,
+		})
+	}
+
+	for _, expr := range selector.MatchExpressions {
+		converted := LabelSelector{Label: expr.Key}
+
+		switch expr.Operator {
+		case metav1.LabelSelectorOpIn:
+			converted.Operator = In
+		case metav1.LabelSelectorOpNotIn:
+			converted.Operator = NotIn
+		case metav1.LabelSelectorOpExists:
+			converted.Operator = Exists
+		case metav1.LabelSelectorOpDoesNotExist:
+			converted.Operator = DoesNotExist
+		default:
+			return nil, fmt.Errorf("%q is not a valid label selector operator", expr.Operator)
+		}
+
+		switch converted.Operator {
+		case In, NotIn:
+			if len(expr.Values) == 0 {
+				return nil, fmt.Errorf("label selector operator %q for %q requires values", expr.Operator, expr.Key)
+			}
+			converted.Values = append([]string(nil), expr.Values...)
+		default:
+			if len(expr.Values) > 0 {
+				return nil, fmt.Errorf("label selector operator %q for %q must not have values", expr.Operator, expr.Key)
+			}
+		}
+
+		selectors = append(selectors, converted)
+	}
+
+	return selectors, nil
+}
+
+// String encodes the selector in the apiserver's field selector syntax
+func (s FieldSelector) String() string {
+	operator := s.Operator
+	if operator == "" || operator == "==" {
+		operator = Equals
+	}
+
+	return s.Field + operator + fieldValueEscaper.Replace(s.Value)
+}
+
+// fieldValueEscaper escapes the characters which have meaning in a field
+// selector, the same way as the apiserver's fields.EscapeValue.
+var fieldValueEscaper = strings.NewReplacer(`\`, `\\`, `,`, `\,`, `=`, `\=`)
+
+// EncodeFieldSelector encodes the selectors as a single fieldSelector
+// query parameter, such as "spec.nodeName=node1,status.phase!=Succeeded".
+// It returns an empty string if there are no selectors.
+func EncodeFieldSelector(selectors []FieldSelector) string {
+	requirements := make([]string, len(selectors))
+	for i, selector := range selectors {
+		requirements[i] = selector.String()
+	}
+
+	return strings.Join(requirements, ",")
+}
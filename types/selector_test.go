@@ -0,0 +1,72 @@
+package types
+
+import (
+	"errors"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+func TestEncodeLabelSelectorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector LabelSelector
+		operator selection.Operator
+		values   []string
+	}{
+		{"empty", LabelSelector{Label: "app", Value: "x"}, selection.Equals, []string{"x"}},
+		{"equals", LabelSelector{Label: "app", Value: "x", Operator: Equals}, selection.Equals, []string{"x"}},
+		{"not equals", LabelSelector{Label: "app", Value: "x", Operator: NotEquals}, selection.NotEquals, []string{"x"}},
+		{"in", LabelSelector{Label: "tier", Values: []string{"a", "b"}, Operator: In}, selection.In, []string{"a", "b"}},
+		{"not in", LabelSelector{Label: "tier", Values: []string{"a", "b"}, Operator: NotIn}, selection.NotIn, []string{"a", "b"}},
+		{"exists", LabelSelector{Label: "tier", Operator: Exists}, selection.Exists, nil},
+		{"does not exist", LabelSelector{Label: "tier", Operator: DoesNotExist}, selection.DoesNotExist, nil},
+		{"less than", LabelSelector{Label: "size", Value: "5", Operator: LessThan}, selection.LessThan, []string{"5"}},
+		{"greater than", LabelSelector{Label: "size", Value: "5", Operator: GreaterThan}, selection.GreaterThan, []string{"5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := EncodeLabelSelector([]LabelSelector{tt.selector})
+			parsed, err := labels.Parse(encoded)
+			if err != nil {
+				t.Fatalf("labels.Parse(%q): %v", encoded, err)
+			}
+
+			want, err := labels.NewRequirement(tt.selector.Label, tt.operator, tt.values)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			requirements, _ := parsed.Requirements()
+			if len(requirements) != 1 || !requirements[0].Equal(*want) {
+				t.Errorf("labels.Parse(%q) = %v, want %v", encoded, parsed, want)
+			}
+		})
+	}
+}
+
+func TestEncodeLabelSelectorCombined(t *testing.T) {
+	encoded := EncodeLabelSelector([]LabelSelector{
+		{Label: "app", Value: "x", Operator: Equals},
+		{Label: "tier", Operator: Exists},
+		{Label: "env", Values: []string{"dev", "prod"}, Operator: NotIn},
+	})
+
+	parsed, err := labels.Parse(encoded)
+	if err != nil {
+		t.Fatalf("labels.Parse(%q): %v", encoded, err)
+	}
+
+	requirements, _ := parsed.Requirements()
+	if len(requirements) != 3 {
+		t.Errorf("labels.Parse(%q) has %d requirements, want 3", encoded, len(requirements))
+	}
+}
+
+func TestLabelSelectorFromMetaNil(t *testing.T) {
+	if _, err := LabelSelectorFromMeta(nil); !errors.Is(err, ErrNilLabelSelector) {
+		t.Errorf("LabelSelectorFromMeta(nil) returned %v, want ErrNilLabelSelector", err)
+	}
+}
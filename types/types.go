@@ -1,6 +1,8 @@
 package types
 
 import (
+	"context"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -16,16 +18,43 @@ type Object[T any] interface {
 	GetLabels() map[string]string
 }
 
+// The operators of a LabelSelector. Where the label selector syntax has
+// a token for the operator, it is used as the operator's value.
 const (
-	Equals      = "=="
-	Exists      = "Exists"
-	LessThan    = "lt"
-	GreaterThan = "gt"
-	NotEquals   = "!="
+	Equals       = "="
+	Exists       = "exists"
+	DoesNotExist = "!"
+	LessThan     = "<"
+	GreaterThan  = ">"
+	NotEquals    = "!="
+	In           = "in"
+	NotIn        = "notin"
 )
 
+// LabelSelector is a single requirement on an object's labels. Selectors
+// are combined with AND. An empty Operator is treated as Equals.
 type LabelSelector struct {
-	Label    string
+	Label string
+
+	// Value is compared with the label for Equals, NotEquals, LessThan
+	// and GreaterThan. LessThan and GreaterThan compare them as integers.
+	Value string
+
+	// Values is the set of values for In and NotIn
+	Values []string
+
+	Operator string
+}
+
+// FieldSelector is a single requirement on one of an object's fields,
+// given by its path, such as "spec.nodeName" or "status.phase". Selectors
+// are combined with AND. Only Equals and NotEquals are supported, and an
+// empty Operator is treated as Equals.
+//
+// The apiserver only supports a few fields for each resource, such as
+// metadata.name and metadata.namespace.
+type FieldSelector struct {
+	Field    string
 	Value    string
 	Operator string
 }
@@ -33,7 +62,29 @@ type LabelSelector struct {
 // ListOptions is reserved to be implemented.
 type ListOptions struct {
 	LabelSelector   []LabelSelector
+	FieldSelector   []FieldSelector
 	ResourceVersion string
+
+	// Limit is the maximum number of items to return in a single List
+	// call. If there are more, the List's Continue is set, and can be
+	// passed back as Continue to fetch the next page.
+	Limit int64
+
+	// Continue is the token from a previous List to fetch the next page
+	// from.
+	Continue string
+
+	// AllowWatchBookmarks asks the apiserver to periodically send BOOKMARK
+	// events on a Watch, which carry only the latest resourceVersion. The
+	// Watcher uses them to keep its resourceVersion fresh, so that it can
+	// reconnect without relisting, and does not return them from Next.
+	AllowWatchBookmarks bool
+
+	// SendInitialEvents makes a Watch start by streaming the current state
+	// of the collection as ADDED events, followed by a BOOKMARK for which
+	// Event.IsInitialEventsEnd is true, instead of a separate List being
+	// needed. It requires bookmarks, so enables them.
+	SendInitialEvents bool
 }
 
 type GroupVersionResource struct {
@@ -49,6 +100,7 @@ const (
 	EventTypeModified EventType = "MODIFIED"
 	EventTypeDeleted  EventType = "DELETED"
 	EventTypeError    EventType = "ERROR"
+	EventTypeBookmark EventType = "BOOKMARK"
 )
 
 // Event represents a single event to a watched resource.
@@ -57,6 +109,21 @@ type Event[T any, PT Object[T]] struct {
 	Object T         `json:"object"`
 }
 
+// InitialEventsEndAnnotation is set on the bookmark which marks the end
+// of the initial events of a watch with SendInitialEvents.
+const InitialEventsEndAnnotation = "k8s.io/initial-events-end"
+
+// IsInitialEventsEnd returns true if the event is the bookmark which
+// marks the end of the initial events of a watch with SendInitialEvents.
+func (e Event[T, PT]) IsInitialEventsEnd() bool {
+	if e.Type != EventTypeBookmark {
+		return false
+	}
+
+	obj, ok := any(PT(&e.Object)).(interface{ GetAnnotations() map[string]string })
+	return ok && obj.GetAnnotations()[InitialEventsEndAnnotation] == "true"
+}
+
 type List[T any, PT Object[T]] struct {
 	metav1.ListMeta `json:"metadata"`
 	Items           []T `json:"items"`
@@ -79,14 +146,18 @@ type WatchInterface[T any, PT Object[T]] interface {
 }
 
 // ObjectAPI wraps all operations on object.
+//
+// Every operation takes a [context.Context] which is attached to the
+// underlying HTTP request. Cancelling the context aborts any in-flight
+// request, and for Watch, stops the watch and closes its result channel.
 type ObjectAPI[T any, PT Object[T]] interface {
-	Get(namespace, name string, _ GetOptions) (T, error)
-	Watch(namespace, name string, _ ListOptions) (WatchInterface[T, PT], error)
-	List(namespace string, _ ListOptions) (*List[T, PT], error)
-	Apply(namespace, name, fieldManager string, force bool, item T) (T, error, EventType)
-	Patch(namespace, name, fieldManager string, item T) (T, error)
-	Create(namespace string, item T) (T, error)
-	Delete(namespace, name string, force bool) (T, error)
+	Get(ctx context.Context, namespace, name string, _ GetOptions) (T, error)
+	Watch(ctx context.Context, namespace, name string, _ ListOptions) (WatchInterface[T, PT], error)
+	List(ctx context.Context, namespace string, _ ListOptions) (*List[T, PT], error)
+	Apply(ctx context.Context, namespace, name, fieldManager string, force bool, item T) (T, error, EventType)
+	Patch(ctx context.Context, namespace, name, fieldManager string, item T) (T, error)
+	Create(ctx context.Context, namespace string, item T) (T, error)
+	Delete(ctx context.Context, namespace, name string, force bool) (T, error)
 	Subresource(subresource string) ObjectAPI[T, PT]
 	Status() ObjectAPI[T, PT]
 }